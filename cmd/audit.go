@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/organizer"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List files no rule matches and rules that match no files",
+	Long: "Audit scans the source like preview, without planning or moving\n" +
+		"anything, and reports the files no rule matches grouped by extension,\n" +
+		"along with how many files each rule wins. Rules that win nothing are\n" +
+		"either dead or shadowed by an earlier rule.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+		printWarnings(cfg)
+
+		auditRecursive, _ := cmd.Flags().GetBool("recursive")
+		auditHidden, _ := cmd.Flags().GetBool("include-hidden")
+		auditTolerant, _ := cmd.Flags().GetBool("tolerant")
+		auditWorkers, _ := cmd.Flags().GetInt("scan-workers")
+		auditProgress, _ := cmd.Flags().GetString("progress")
+		auditOutput, _ := cmd.Flags().GetString("output")
+
+		if auditOutput != internal.OutputTable && auditOutput != internal.OutputJSON {
+			return configError(cmd, fmt.Errorf("invalid output format %q for audit: must be table or json", auditOutput))
+		}
+
+		onProgress, stopProgress, err := startProgress(auditProgress)
+		if err != nil {
+			return configError(cmd, err)
+		}
+
+		opts := organizer.Options{
+			Recursive:     auditRecursive,
+			IncludeHidden: auditHidden,
+			Tolerant:      auditTolerant,
+			ScanWorkers:   auditWorkers,
+			Progress:      onProgress,
+		}
+
+		cov, err := organizer.Audit(cmd.Context(), cfg, opts)
+		stopProgress()
+		if cov == nil {
+			return fmt.Errorf("auditing source: %w", err)
+		}
+
+		cmd.SilenceUsage = true
+		if auditOutput == internal.OutputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(cov); err != nil {
+				return fmt.Errorf("writing JSON output: %w", err)
+			}
+		} else if !quiet {
+			printCoverage(cov)
+		}
+		if err != nil {
+			return fmt.Errorf("auditing source: %w", err)
+		}
+
+		if n := len(cov.ScanErrors); n > 0 {
+			printScanErrors(cov.ScanErrors)
+			return withExitCode(exitPartial, fmt.Errorf("%d path(s) could not be scanned", n))
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.Flags().BoolP("recursive", "r", false, "scan directories recursively")
+	auditCmd.Flags().Bool("include-hidden", false, "include hidden files and directories")
+	auditCmd.Flags().Int("scan-workers", 1, "number of directories to read concurrently in recursive scans")
+	auditCmd.Flags().String("progress", internal.ProgressAuto, "show progress: auto, always, or never")
+	auditCmd.Flags().StringP("output", "o", internal.OutputTable, "output format: table or json")
+	auditCmd.Flags().Bool("tolerant", false, "keep going when paths cannot be scanned and report them at the end")
+	rootCmd.AddCommand(auditCmd)
+}
+
+// printCoverage renders an audit as two tables: unmatched files by extension
+// and files won by each rule.
+func printCoverage(cov *organizer.Coverage) {
+	fmt.Printf("Scanned %d file(s): %d matched, %d unmatched\n", cov.Scanned, cov.Matched, cov.UnmatchedFiles())
+
+	if len(cov.Unmatched) > 0 {
+		fmt.Println("\nUnmatched files by extension:")
+		rows := make([][]string, 0, len(cov.Unmatched))
+		for _, g := range cov.Unmatched {
+			ext := g.Extension
+			if ext == "" {
+				ext = "(none)"
+			}
+			examples := make([]string, len(g.Examples))
+			for i, p := range g.Examples {
+				examples[i] = shortPath(p)
+			}
+			example := strings.Join(examples, ", ")
+			if g.Files > len(g.Examples) {
+				example += ", …"
+			}
+			rows = append(rows, []string{ext, fmt.Sprint(g.Files), config.FormatSize(g.Bytes), example})
+		}
+		printColumns([]string{"Extension", "Files", "Size", "Examples"}, rows)
+	}
+
+	fmt.Println("\nFiles per rule:")
+	rows := make([][]string, 0, len(cov.Rules))
+	for _, r := range cov.Rules {
+		rows = append(rows, []string{r.Label(), fmt.Sprint(r.Files), config.FormatSize(r.Bytes)})
+	}
+	printColumns([]string{"Rule", "Files", "Size"}, rows)
+
+	if dead := cov.DeadRules(); len(dead) > 0 {
+		fmt.Printf("\n%d rule(s) matched no files: %s\n", len(dead), strings.Join(dead, ", "))
+	}
+}
+
+// printColumns prints rows under header, padding each column to its widest
+// cell. The last column is not padded.
+func printColumns(header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	printRow := func(cells []string) {
+		var b strings.Builder
+		b.WriteString(" ")
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				fmt.Fprintf(&b, " %s", cell)
+			} else {
+				fmt.Fprintf(&b, " %-*s ", widths[i], cell)
+			}
+		}
+		fmt.Println(strings.TrimRight(b.String(), " "))
+	}
+
+	printRow(header)
+	sep := make([]string, len(header))
+	for i, w := range widths {
+		sep[i] = strings.Repeat("─", w)
+	}
+	printRow(sep)
+	for _, row := range rows {
+		printRow(row)
+	}
+}
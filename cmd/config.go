@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration after includes are merged",
+	Long: "Show loads the config and every file it includes, then prints the\n" +
+		"merged result as YAML, with presets and categories expanded. Each rule\n" +
+		"is annotated with the file it came from.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+		printWarnings(cfg)
+
+		var doc yaml.Node
+		if err := doc.Encode(cfg); err != nil {
+			return fmt.Errorf("encoding config: %w", err)
+		}
+		annotateOrigins(&doc, cfg)
+		flowLists(&doc)
+
+		cmd.SilenceUsage = true
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+		return enc.Close()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// annotateOrigins comments doc, the encoded form of cfg, with the files that
+// were merged and the file each rule came from.
+func annotateOrigins(doc *yaml.Node, cfg *config.Config) {
+	doc.HeadComment = "Effective configuration merged from:"
+	for _, f := range cfg.Files {
+		doc.HeadComment += "\n  " + shortPath(f)
+	}
+
+	annotateRules(doc, cfg.Rules)
+	if sources := mappingValue(doc, "sources"); sources != nil {
+		for i, item := range sources.Content {
+			if i < len(cfg.Sources) {
+				annotateRules(item, cfg.Sources[i].Rules)
+			}
+		}
+	}
+}
+
+// annotateRules comments each rule in the rules list of the mapping n with
+// the file it came from.
+func annotateRules(n *yaml.Node, rules []config.RuleConfig) {
+	list := mappingValue(n, "rules")
+	if list == nil {
+		return
+	}
+	for j, item := range list.Content {
+		if j < len(rules) && rules[j].Origin != "" {
+			item.HeadComment = "from " + shortPath(rules[j].Origin)
+		}
+	}
+}
+
+// mappingValue returns the value of key in the mapping n, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// flowLists switches every list of plain values under n to flow style, so
+// extension lists print on one line as they are usually written.
+func flowLists(n *yaml.Node) {
+	if n.Kind == yaml.SequenceNode && !slices.ContainsFunc(n.Content, func(c *yaml.Node) bool { return c.Kind != yaml.ScalarNode }) {
+		n.Style = yaml.FlowStyle
+	}
+	for _, c := range n.Content {
+		flowLists(c)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/devaloi/forg/internal/organizer"
+	"github.com/devaloi/forg/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes, so cron jobs and CI can tell outcomes apart.
+const (
+	exitOK          = 0
+	exitGeneral     = 1
+	exitConfig      = 2
+	exitPartial     = 3
+	exitFailure     = 4
+	exitInterrupted = 130
+)
+
+// exitError attaches a process exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode wraps err so that ExitCode returns code for it.
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute:
+// 0 for success, 2 for configuration and usage errors, 3 when some files
+// could not be moved or scanned, 4 when every move failed, 130 when the run
+// was interrupted and 1 for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	return exitGeneral
+}
+
+// configError reports a problem with the configuration or flags. Usage is not
+// printed since the flags themselves parsed fine.
+func configError(cmd *cobra.Command, err error) error {
+	cmd.SilenceUsage = true
+	return withExitCode(exitConfig, err)
+}
+
+// reportFailure prints the error section for report and returns an error
+// carrying the matching exit code, or nil if nothing went wrong.
+func reportFailure(cmd *cobra.Command, report *organizer.Report) error {
+	printFailures(report)
+
+	failed, unscanned := len(report.Failures), len(report.ScanErrors)
+	switch {
+	case failed == 0 && unscanned == 0:
+		return nil
+	case failed > 0 && failed == report.Total():
+		cmd.SilenceUsage = true
+		return withExitCode(exitFailure, fmt.Errorf("all %d operation(s) failed", failed))
+	case failed > 0:
+		cmd.SilenceUsage = true
+		return withExitCode(exitPartial, fmt.Errorf("%d of %d operation(s) failed", failed, report.Total()))
+	default:
+		cmd.SilenceUsage = true
+		return withExitCode(exitPartial, fmt.Errorf("%d path(s) could not be scanned", unscanned))
+	}
+}
+
+// printFailures lists paths that could not be scanned and operations that
+// failed. It writes to stderr and ignores quiet mode, since these are errors.
+func printFailures(report *organizer.Report) {
+	printScanErrors(report.ScanErrors)
+	if n := len(report.Failures); n > 0 {
+		fmt.Fprintf(os.Stderr, "%d operation(s) failed:\n", n)
+		for _, f := range report.Failures {
+			fmt.Fprintf(os.Stderr, "  %s (rule: %s): %v\n", f.Op.Source, f.Op.RuleName, f.Err)
+		}
+	}
+}
+
+// printScanErrors lists paths that could not be scanned on stderr.
+func printScanErrors(errs []scanner.ScanError) {
+	if len(errs) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%d path(s) could not be scanned:\n", len(errs))
+	for _, se := range errs {
+		fmt.Fprintf(os.Stderr, "  %v\n", se)
+	}
+}
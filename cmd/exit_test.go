@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/forg/internal/organizer"
+	"github.com/devaloi/forg/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"general error", errors.New("boom"), exitGeneral},
+		{"config error", configError(&cobra.Command{}, errors.New("bad config")), exitConfig},
+		{"partial", withExitCode(exitPartial, errors.New("some failed")), exitPartial},
+		{"all failed", withExitCode(exitFailure, errors.New("all failed")), exitFailure},
+		{"interrupted", fmt.Errorf("running organizer: interrupted: %w", context.Canceled), exitInterrupted},
+		{"wrapped exit code", fmt.Errorf("outer: %w", withExitCode(exitConfig, errors.New("inner"))), exitConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportFailure(t *testing.T) {
+	failure := organizer.OpError{
+		Op:  organizer.MoveOp{Source: "/src/a.txt", RuleName: "r"},
+		Err: errors.New("permission denied"),
+	}
+	scanErr := scanner.ScanError{Path: "/src/locked", Err: errors.New("permission denied")}
+
+	tests := []struct {
+		name      string
+		report    organizer.Report
+		want      int
+		wantError string
+	}{
+		{
+			name:   "no failures",
+			report: organizer.Report{Moved: 3},
+			want:   exitOK,
+		},
+		{
+			name:      "partial",
+			report:    organizer.Report{Moved: 2, Errors: 1, Failures: []organizer.OpError{failure}},
+			want:      exitPartial,
+			wantError: "1 of 3 operation(s) failed",
+		},
+		{
+			name:      "all failed",
+			report:    organizer.Report{Errors: 2, Failures: []organizer.OpError{failure, failure}},
+			want:      exitFailure,
+			wantError: "all 2 operation(s) failed",
+		},
+		{
+			name:      "skipped and failed",
+			report:    organizer.Report{Skipped: 5, Errors: 1, Failures: []organizer.OpError{failure}},
+			want:      exitPartial,
+			wantError: "1 of 6 operation(s) failed",
+		},
+		{
+			name:      "scan errors only",
+			report:    organizer.Report{Moved: 1, ScanErrors: []scanner.ScanError{scanErr}},
+			want:      exitPartial,
+			wantError: "1 path(s) could not be scanned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := reportFailure(&cobra.Command{}, &tt.report)
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("exit code = %d, want %d (error %v)", got, tt.want, err)
+			}
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("reportFailure() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("reportFailure() = %v, want an error containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/organizer"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <file>...",
+	Short: "Show how each rule evaluates a file and where it would go",
+	Long: "Explain runs each file through every rule in order and shows each\n" +
+		"criterion's verdict with the values compared, the winning rule, and\n" +
+		"the final destination after conflict handling. Nothing is moved.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+
+		explainRecursive, _ := cmd.Flags().GetBool("recursive")
+		explainHidden, _ := cmd.Flags().GetBool("include-hidden")
+		opts := organizer.Options{
+			Recursive:     explainRecursive,
+			IncludeHidden: explainHidden,
+		}
+
+		cmd.SilenceUsage = true
+		for i, path := range args {
+			ex, err := organizer.Explain(cfg, opts, path)
+			if err != nil {
+				return fmt.Errorf("explaining %s: %w", path, err)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			printExplanation(ex, cfg.Conflict)
+		}
+		return nil
+	},
+}
+
+func init() {
+	explainCmd.Flags().BoolP("recursive", "r", false, "explain as a recursive scan would")
+	explainCmd.Flags().Bool("include-hidden", false, "explain as a scan including hidden files would")
+	rootCmd.AddCommand(explainCmd)
+}
+
+// printExplanation renders an explanation: the file, each rule's verdicts in
+// order, and the outcome.
+func printExplanation(ex *organizer.Explanation, conflict string) {
+	f := ex.File
+	fmt.Printf("File: %s\n", shortPath(f.Path))
+	fmt.Printf("  %s, %s, modified %s, depth %d\n",
+		f.Type, config.FormatSize(f.Size), f.ModTime.Local().Format("2006-01-02 15:04"), f.Depth)
+	if ex.Skipped != "" {
+		fmt.Printf("  not scanned: %s\n", ex.Skipped)
+	}
+
+	for i, t := range ex.Traces {
+		verdict := "no match"
+		switch {
+		case t.Matched && t.Rule == ex.Rule:
+			verdict = "match (wins)"
+		case t.Matched:
+			verdict = "match (not used: an earlier rule wins)"
+		}
+		fmt.Printf("\nRule %d %q: %s\n", i+1, t.Rule.Name, verdict)
+
+		width := 0
+		for _, v := range t.Verdicts {
+			width = max(width, len(v.Criterion))
+		}
+		for _, v := range t.Verdicts {
+			mark := "✗"
+			if v.Matched {
+				mark = "✓"
+			}
+			fmt.Printf("  %s %-*s  %s\n", mark, width, v.Criterion, v.Detail)
+		}
+	}
+
+	fmt.Println()
+	if ex.Rule == nil {
+		fmt.Println("Result:      no rule matches; the file stays where it is")
+		return
+	}
+
+	fmt.Printf("Rule:        %s\n", ex.Rule.Name)
+	fmt.Printf("Destination: %s\n", shortPath(ex.Destination))
+	if conflict == "" {
+		conflict = internal.ConflictSkip
+	}
+
+	var result string
+	switch ex.Action {
+	case internal.ActionRename:
+		result = fmt.Sprintf("destination exists; %s -> %s", conflict, shortPath(ex.Final))
+	case internal.ActionOverwrite:
+		result = fmt.Sprintf("destination exists; %s replaces it", conflict)
+	case internal.ActionSkip:
+		result = fmt.Sprintf("destination exists; %s leaves the file in place", conflict)
+	default:
+		result = ex.Action
+	}
+	if ex.Skipped != "" {
+		result = "not scanned, so the file stays where it is (otherwise: " + result + ")"
+	}
+	fmt.Printf("Result:      %s\n", result)
+}
@@ -3,23 +3,46 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/category"
 	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/scanner"
+	"github.com/devaloi/forg/internal/starter"
 	"github.com/spf13/cobra"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Generate a sample .forg.yaml configuration file",
-	RunE: func(_ *cobra.Command, _ []string) error {
+	Long: "Init writes a sample .forg.yaml to the current directory.\n\n" +
+		"With --from-dir, it scans the directory instead and writes a config with\n" +
+		"one rule per category of files found there (images, documents, archives\n" +
+		"and so on), plus rules that set aside large and old files in categories\n" +
+		"that have them.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		const filename = internal.DefaultConfigFile
 
+		initFromDir, _ := cmd.Flags().GetString("from-dir")
+		initRecursive, _ := cmd.Flags().GetBool("recursive")
+
 		if _, err := os.Stat(filename); err == nil {
 			return fmt.Errorf("%s already exists; remove it first or edit it directly", filename)
 		}
 
-		if err := os.WriteFile(filename, []byte(config.SampleConfig()), 0o600); err != nil {
+		content := config.SampleConfig()
+		if initFromDir != "" {
+			var err error
+			content, err = starterConfig(cmd, initFromDir, initRecursive)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
 			return fmt.Errorf("writing %s: %w", filename, err)
 		}
 
@@ -29,5 +52,35 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
+	initCmd.Flags().String("from-dir", "", "generate rules for the kinds of files found in this directory")
+	initCmd.Flags().BoolP("recursive", "r", false, "with --from-dir, scan subdirectories too")
 	rootCmd.AddCommand(initCmd)
 }
+
+// starterConfig scans dir and generates a config with rules for the
+// categories of files found in it.
+func starterConfig(cmd *cobra.Command, dir string, recursive bool) (string, error) {
+	expanded, err := config.ExpandPath(dir)
+	if err != nil {
+		return "", fmt.Errorf("expanding %s: %w", dir, err)
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", dir, err)
+	}
+
+	files := scanner.New(scanner.Options{Recursive: recursive}).Files(cmd.Context(), abs)
+	profile, err := starter.Analyze(files, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("scanning %s: %w", dir, err)
+	}
+
+	content, err := starter.Generate(shortPath(abs), profile)
+	if err != nil {
+		return "", fmt.Errorf("generating config for %s: %w", dir, err)
+	}
+
+	logger("Scanned %d file(s) in %s: %d of %d categories present.",
+		profile.Files, shortPath(abs), len(profile.Categories), len(category.Builtin()))
+	return content, nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/organizer"
+)
+
+// jsonReport is the document printed by --output json.
+type jsonReport struct {
+	DryRun      bool                  `json:"dry_run"`
+	Interrupted bool                  `json:"interrupted,omitempty"`
+	Moved       int                   `json:"moved"`
+	Skipped     int                   `json:"skipped"`
+	Conflicts   int                   `json:"conflicts"`
+	Errors      int                   `json:"errors"`
+	Results     []organizer.Result    `json:"results"`
+	ScanErrors  []jsonScanError       `json:"scan_errors,omitempty"`
+	Collisions  []organizer.Collision `json:"collisions,omitempty"`
+}
+
+// jsonScanError is a path that could not be scanned.
+type jsonScanError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// validateOutput checks an --output value before any work is done.
+func validateOutput(format string) error {
+	if !internal.ValidOutputFormat(format) {
+		return fmt.Errorf("invalid output format %q: must be table, json, jsonl, or csv", format)
+	}
+	return nil
+}
+
+// startOutput prepares opts for printing the run's results in format and
+// returns the function that prints report once the run is over. The table
+// and json formats need the whole run, so they keep every result in the
+// report; jsonl and csv rows are written as each operation finishes, so
+// memory does not grow with the number of files. The table format respects
+// quiet mode; the machine-readable formats are always printed since they were
+// asked for explicitly.
+func startOutput(format string, opts *organizer.Options) func(*organizer.Report) error {
+	var rows *rowWriter
+	switch format {
+	case internal.OutputJSON, internal.OutputTable:
+		opts.KeepResults = true
+	case internal.OutputJSONL:
+		enc := json.NewEncoder(os.Stdout)
+		rows = &rowWriter{write: func(r organizer.Result) error { return enc.Encode(r) }}
+	case internal.OutputCSV:
+		// The header goes out with the first row, so nothing reaches
+		// stdout when the run fails before it starts.
+		w := csv.NewWriter(os.Stdout)
+		started := false
+		start := func() {
+			if !started {
+				_ = w.Write([]string{"source", "destination", "rule", "action", "status", "error"})
+				started = true
+			}
+		}
+		rows = &rowWriter{
+			write: func(r organizer.Result) error {
+				start()
+				return w.Write([]string{r.Source, r.Destination, r.Rule, r.Action, r.Status, r.Error})
+			},
+			flush: func() error {
+				start()
+				w.Flush()
+				return w.Error()
+			},
+		}
+	}
+	if rows != nil {
+		opts.OnResult = rows.add
+	}
+
+	return func(report *organizer.Report) error {
+		switch {
+		case rows != nil:
+			if err := rows.close(); err != nil {
+				return fmt.Errorf("writing %s output: %w", format, err)
+			}
+			return nil
+		case format == internal.OutputJSON:
+			return writeJSON(report)
+		default:
+			printReport(report)
+			return nil
+		}
+	}
+}
+
+// rowWriter streams one output row per operation, remembering the first
+// write error so the run itself is not interrupted by it.
+type rowWriter struct {
+	write func(organizer.Result) error
+	flush func() error
+	err   error
+}
+
+// add writes the row for r unless an earlier row failed.
+func (w *rowWriter) add(r organizer.Result) {
+	if w.err == nil {
+		w.err = w.write(r)
+	}
+}
+
+// close flushes the rows and returns the first error.
+func (w *rowWriter) close() error {
+	if w.flush != nil && w.err == nil {
+		w.err = w.flush()
+	}
+	return w.err
+}
+
+// writeJSON prints report as a single JSON document.
+func writeJSON(report *organizer.Report) error {
+	doc := jsonReport{
+		DryRun:      report.DryRun,
+		Interrupted: report.Interrupted,
+		Moved:       report.Moved,
+		Skipped:     report.Skipped,
+		Conflicts:   report.Conflicts,
+		Errors:      report.Errors,
+		Results:     report.Results,
+		Collisions:  report.Collisions,
+	}
+	if doc.Results == nil {
+		doc.Results = []organizer.Result{}
+	}
+	for _, se := range report.ScanErrors {
+		doc.ScanErrors = append(doc.ScanErrors, jsonScanError{Path: se.Path, Error: se.Error()})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing JSON output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/devaloi/forg/internal/category"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var presetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "Inspect the categories rules can use as presets",
+	Long: "Categories are named lists of extensions with a suggested destination.\n" +
+		"A rule can match one with \"match: {category: images}\" or take its\n" +
+		"name, extensions and destination from it with \"preset: images\".\n\n" +
+		"The built-in categories can be extended or overridden, and new ones\n" +
+		"added, under \"categories:\" in the config. These commands show the\n" +
+		"result when a config is present, and the built-in catalog otherwise.",
+}
+
+var presetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the available categories",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		catalog, err := presetCatalog(cmd)
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]string, 0, len(catalog))
+		for _, c := range catalog {
+			rows = append(rows, []string{c.Name, fmt.Sprint(len(c.Extensions)), orDash(c.Folder), c.Origin, c.Description})
+		}
+		printColumns([]string{"Name", "Extensions", "Folder", "Origin", "Description"}, rows)
+		return nil
+	},
+}
+
+var presetsShowCmd = &cobra.Command{
+	Use:   "show <name>...",
+	Short: "Show the extensions and folder of categories",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		catalog, err := presetCatalog(cmd)
+		if err != nil {
+			return err
+		}
+
+		for i, name := range args {
+			c, ok := catalog.Lookup(name)
+			if !ok {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("unknown category %q; run 'forg presets list' to see them all", name)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s (%s)\n", c.Name, c.Origin)
+			if c.Description != "" {
+				fmt.Printf("  %s\n", c.Description)
+			}
+			fmt.Printf("  Folder:     %s\n", orDash(c.Folder))
+			fmt.Printf("  Extensions: %s\n", strings.Join(c.Extensions, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	presetsCmd.AddCommand(presetsListCmd)
+	presetsCmd.AddCommand(presetsShowCmd)
+	rootCmd.AddCommand(presetsCmd)
+}
+
+// presetCatalog returns the categories of the config, or the built-in ones
+// when the default config file does not exist.
+func presetCatalog(cmd *cobra.Command) (category.Catalog, error) {
+	if !cmd.Flags().Changed("config") {
+		if _, err := os.Stat(cfgFile); errors.Is(err, fs.ErrNotExist) {
+			return category.Builtin(), nil
+		}
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, configError(cmd, fmt.Errorf("loading config: %w", err))
+	}
+	printWarnings(cfg)
+	return cfg.Catalog(), nil
+}
+
+// orDash returns s, or a dash when s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
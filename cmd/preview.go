@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/devaloi/forg/internal"
 	"github.com/devaloi/forg/internal/config"
 	"github.com/devaloi/forg/internal/organizer"
 	"github.com/spf13/cobra"
@@ -14,32 +15,69 @@ var previewCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		cfg, err := config.Load(cfgFile)
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
 		}
+		printWarnings(cfg)
 
 		previewRecursive, _ := cmd.Flags().GetBool("recursive")
 		previewHidden, _ := cmd.Flags().GetBool("include-hidden")
+		previewTolerant, _ := cmd.Flags().GetBool("tolerant")
+		previewWorkers, _ := cmd.Flags().GetInt("scan-workers")
+		previewCache, _ := cmd.Flags().GetBool("cache")
+		previewProgress, _ := cmd.Flags().GetString("progress")
+		previewOutput, _ := cmd.Flags().GetString("output")
+
+		if err := validateOutput(previewOutput); err != nil {
+			return configError(cmd, err)
+		}
+
+		onProgress, stopProgress, err := startProgress(previewProgress)
+		if err != nil {
+			return configError(cmd, err)
+		}
 
 		opts := organizer.Options{
 			DryRun:        true,
 			Verbose:       verbose,
 			Recursive:     previewRecursive,
 			IncludeHidden: previewHidden,
-			ConfigPath:    cfgFile,
+			Tolerant:      previewTolerant,
+			ScanWorkers:   previewWorkers,
+			// Keep the preview listing stable when directories are read
+			// in parallel.
+			SortScan:   previewWorkers > 1,
+			Cache:      previewCache,
+			Progress:   onProgress,
+			ConfigPath: cfgFile,
 		}
 
-		report, err := organizer.Run(cfg, opts, logger)
-		if err != nil {
+		writeOutput := startOutput(previewOutput, &opts)
+		report, err := organizer.Run(cmd.Context(), cfg, opts, logger)
+		stopProgress()
+		if report == nil {
 			return fmt.Errorf("running preview: %w", err)
 		}
 
-		printReport(report)
-		return nil
+		cmd.SilenceUsage = true
+		if outErr := writeOutput(report); outErr != nil {
+			return outErr
+		}
+		printCollisions(report)
+		if err != nil {
+			printFailures(report)
+			return fmt.Errorf("running preview: %w", err)
+		}
+		return reportFailure(cmd, report)
 	},
 }
 
 func init() {
 	previewCmd.Flags().BoolP("recursive", "r", false, "scan directories recursively")
 	previewCmd.Flags().Bool("include-hidden", false, "include hidden files and directories")
+	previewCmd.Flags().Int("scan-workers", 1, "number of directories to read concurrently in recursive scans")
+	previewCmd.Flags().Bool("cache", false, "skip rule evaluation for files unchanged since the last cached run")
+	previewCmd.Flags().String("progress", internal.ProgressAuto, "show progress: auto, always, or never")
+	previewCmd.Flags().StringP("output", "o", internal.OutputTable, "output format: table, json, jsonl, or csv")
+	previewCmd.Flags().Bool("tolerant", false, "keep going when paths cannot be scanned and report them at the end")
 	rootCmd.AddCommand(previewCmd)
 }
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/progress"
+)
+
+const (
+	// barInterval is how often the progress bar is redrawn on a terminal.
+	barInterval = 200 * time.Millisecond
+	// logInterval is how often a progress line is logged when stderr is not
+	// a terminal.
+	logInterval = 10 * time.Second
+	// barWidth is the number of cells in the progress bar.
+	barWidth = 24
+)
+
+// stderrMu serialises writes to stderr so log lines and the progress bar do
+// not interleave. barVisible is true while a bar is drawn on the last line.
+var (
+	stderrMu   sync.Mutex
+	barVisible bool
+)
+
+// startProgress begins reporting progress according to mode. It returns the
+// function to pass as organizer.Options.Progress, or nil when no progress is
+// shown, and a function that stops reporting and clears the bar.
+func startProgress(mode string) (func(progress.Event), func(), error) {
+	if !internal.ValidProgressMode(mode) {
+		return nil, nil, fmt.Errorf("invalid progress mode %q: must be auto, always, or never", mode)
+	}
+
+	if quiet || mode == internal.ProgressNever {
+		return nil, func() {}, nil
+	}
+
+	tracker := progress.NewTracker()
+	render, interval := logProgress, logInterval
+	if mode == internal.ProgressAlways || isTerminal(os.Stderr) {
+		render, interval = drawBar, barInterval
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				render(tracker.Snapshot())
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return tracker.Observe, func() {
+		close(stop)
+		<-done
+		clearBar()
+	}, nil
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// drawBar redraws the progress bar on the current line of stderr.
+func drawBar(s progress.Snapshot) {
+	var line string
+	if !s.ScanDone {
+		line = fmt.Sprintf("scanning: %d found, %d matched, %d done (%s)",
+			s.Scanned, s.Matched, s.Done(), config.FormatSize(s.Bytes))
+	} else {
+		filled := barWidth
+		if s.Matched > 0 {
+			filled = int(s.Done() * barWidth / s.Matched)
+		}
+		line = fmt.Sprintf("[%s%s] %d/%d files  %s/%s",
+			strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled),
+			s.Done(), s.Matched, config.FormatSize(s.Bytes), config.FormatSize(s.TotalBytes))
+		if eta, ok := s.ETA(); ok {
+			line += "  ETA " + eta.Round(time.Second).String()
+		}
+	}
+
+	stderrMu.Lock()
+	defer stderrMu.Unlock()
+	fmt.Fprint(os.Stderr, "\r\033[K"+line)
+	barVisible = true
+}
+
+// logProgress writes a single progress line to stderr.
+func logProgress(s progress.Snapshot) {
+	msg := fmt.Sprintf("progress: %d scanned, %d matched, %d done (%s moved)",
+		s.Scanned, s.Matched, s.Done(), config.FormatSize(s.Bytes))
+	if eta, ok := s.ETA(); ok {
+		msg += ", ETA " + eta.Round(time.Second).String()
+	}
+	logger("%s", msg)
+}
+
+// clearBar erases the progress bar if one is drawn.
+func clearBar() {
+	stderrMu.Lock()
+	defer stderrMu.Unlock()
+	clearBarLocked()
+}
+
+func clearBarLocked() {
+	if barVisible {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		barVisible = false
+	}
+}
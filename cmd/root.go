@@ -2,10 +2,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -23,20 +27,46 @@ var rootCmd = &cobra.Command{
 	Version: version,
 }
 
-// Execute runs the root command and returns any error.
+// Execute runs the root command and returns any error; ExitCode maps it to
+// the process exit code. The first SIGINT or SIGTERM cancels the command's
+// context so it can stop after the operation in flight; a second one
+// terminates the process immediately.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", internal.DefaultConfigFile, "path to configuration file")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress all non-error output")
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return withExitCode(exitConfig, err)
+	})
 }
 
 // logger prints a formatted message to stderr unless quiet mode is enabled.
+// A progress bar on the same line is cleared first and redrawn on its next
+// update.
 func logger(format string, args ...interface{}) {
 	if !quiet {
+		stderrMu.Lock()
+		defer stderrMu.Unlock()
+		clearBarLocked()
 		fmt.Fprintf(os.Stderr, format+"\n", args...)
 	}
 }
+
+// printWarnings logs any non-fatal problems found while loading cfg.
+func printWarnings(cfg *config.Config) {
+	for _, w := range cfg.Warnings {
+		logger("warning: %s", w)
+	}
+}
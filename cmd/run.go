@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/devaloi/forg/internal"
 	"github.com/devaloi/forg/internal/config"
 	"github.com/devaloi/forg/internal/organizer"
 	"github.com/spf13/cobra"
@@ -15,15 +16,30 @@ var (
 	dryRun        bool
 	recursive     bool
 	includeHidden bool
+	tolerant      bool
+	jobs          int
+	scanWorkers   int
+	useCache      bool
+	progressMode  string
+	outputFormat  string
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Execute organizing rules and move files",
-	RunE: func(_ *cobra.Command, _ []string) error {
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		cfg, err := config.Load(cfgFile)
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+		printWarnings(cfg)
+
+		if err := validateOutput(outputFormat); err != nil {
+			return configError(cmd, err)
+		}
+		onProgress, stopProgress, err := startProgress(progressMode)
+		if err != nil {
+			return configError(cmd, err)
 		}
 
 		opts := organizer.Options{
@@ -31,16 +47,31 @@ var runCmd = &cobra.Command{
 			Verbose:       verbose,
 			Recursive:     recursive,
 			IncludeHidden: includeHidden,
+			Tolerant:      tolerant,
+			Jobs:          jobs,
+			ScanWorkers:   scanWorkers,
+			Cache:         useCache,
+			Progress:      onProgress,
 			ConfigPath:    cfgFile,
 		}
 
-		report, err := organizer.Run(cfg, opts, logger)
-		if err != nil {
+		writeOutput := startOutput(outputFormat, &opts)
+		report, err := organizer.Run(cmd.Context(), cfg, opts, logger)
+		stopProgress()
+		if report == nil {
 			return fmt.Errorf("running organizer: %w", err)
 		}
 
-		printReport(report)
-		return nil
+		cmd.SilenceUsage = true
+		if outErr := writeOutput(report); outErr != nil {
+			return outErr
+		}
+		printCollisions(report)
+		if err != nil {
+			printFailures(report)
+			return fmt.Errorf("running organizer: %w", err)
+		}
+		return reportFailure(cmd, report)
 	},
 }
 
@@ -48,6 +79,12 @@ func init() {
 	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would happen without moving files")
 	runCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "scan directories recursively")
 	runCmd.Flags().BoolVar(&includeHidden, "include-hidden", false, "include hidden files and directories")
+	runCmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "number of files to move concurrently")
+	runCmd.Flags().IntVar(&scanWorkers, "scan-workers", 1, "number of directories to read concurrently in recursive scans")
+	runCmd.Flags().BoolVar(&useCache, "cache", false, "skip rule evaluation for files unchanged since the last cached run")
+	runCmd.Flags().StringVar(&progressMode, "progress", internal.ProgressAuto, "show progress: auto, always, or never")
+	runCmd.Flags().StringVarP(&outputFormat, "output", "o", internal.OutputTable, "output format: table, json, jsonl, or csv")
+	runCmd.Flags().BoolVar(&tolerant, "tolerant", false, "keep going when paths cannot be scanned and report them at the end")
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -57,6 +94,10 @@ func printReport(report *organizer.Report) {
 		return
 	}
 
+	if report.Interrupted {
+		fmt.Println("Interrupted: showing the files processed before the run stopped.")
+	}
+
 	if report.DryRun {
 		fmt.Println("--- Dry Run ---")
 		if len(report.Operations) == 0 {
@@ -68,8 +109,37 @@ func printReport(report *organizer.Report) {
 		return
 	}
 
-	fmt.Printf("Moved %d file(s) (%d skipped, %d conflict(s))\n",
-		report.Moved, report.Skipped, report.Conflicts)
+	if len(report.Results) > 0 {
+		printResults(report.Results)
+		fmt.Println()
+	}
+	fmt.Printf("Moved %d file(s) (%d skipped, %d conflict(s), %d failed)\n",
+		report.Moved, report.Skipped, report.Conflicts, report.Errors)
+}
+
+// printResults renders one row per operation of a real run, with the same
+// fields as the machine-readable formats.
+func printResults(results []organizer.Result) {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{shortPath(r.Source), shortPath(r.Destination), r.Rule, r.Action, r.Status, r.Error})
+	}
+	printColumns([]string{"Source", "Destination", "Rule", "Action", "Status", "Error"}, rows)
+}
+
+// printCollisions warns about destination paths that files from more than
+// one source were planned to move to.
+func printCollisions(report *organizer.Report) {
+	if len(report.Collisions) == 0 {
+		return
+	}
+	logger("warning: %d destination(s) planned from more than one source; the conflict strategy applies to all but the first file:", len(report.Collisions))
+	for _, c := range report.Collisions {
+		logger("  %s", shortPath(c.Destination))
+		for _, f := range c.Files {
+			logger("    <- %s", shortPath(f))
+		}
+	}
 }
 
 // printTable renders a formatted table of move operations.
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var installServiceCmd = &cobra.Command{
+	Use:   "install-service",
+	Short: "Generate systemd user units that run forg automatically",
+	Long: "install-service writes a forg.service unit plus a forg.timer (timer mode)\n" +
+		"or forg.path (path mode) unit into the systemd user unit directory.\n" +
+		"Use --print to write the units to stdout instead.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+
+		cfgPath, err := config.ExpandPath(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("expanding config path: %w", err))
+		}
+		cfgPath, err = filepath.Abs(cfgPath)
+		if err != nil {
+			return fmt.Errorf("resolving config path: %w", err)
+		}
+
+		var sources []string
+		for _, src := range cfg.AllSources() {
+			source, err := config.ExpandPath(src.Path)
+			if err != nil {
+				return configError(cmd, fmt.Errorf("expanding source path: %w", err))
+			}
+			source, err = filepath.Abs(source)
+			if err != nil {
+				return fmt.Errorf("resolving source path: %w", err)
+			}
+			sources = append(sources, source)
+		}
+
+		binary, _ := cmd.Flags().GetString("binary")
+		if binary == "" {
+			binary, err = os.Executable()
+			if err != nil {
+				return fmt.Errorf("locating forg executable: %w", err)
+			}
+		}
+		binary, err = filepath.Abs(binary)
+		if err != nil {
+			return fmt.Errorf("resolving binary path: %w", err)
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		mode, _ := cmd.Flags().GetString("mode")
+		schedule, _ := cmd.Flags().GetString("schedule")
+		printOnly, _ := cmd.Flags().GetBool("print")
+		svcRecursive, _ := cmd.Flags().GetBool("recursive")
+		svcHidden, _ := cmd.Flags().GetBool("include-hidden")
+
+		var args []string
+		if svcRecursive {
+			args = append(args, "--recursive")
+		}
+		if svcHidden {
+			args = append(args, "--include-hidden")
+		}
+
+		units, err := service.Generate(service.Options{
+			Name:       name,
+			Mode:       mode,
+			Binary:     binary,
+			ConfigPath: cfgPath,
+			Sources:    sources,
+			Schedule:   schedule,
+			Args:       args,
+		})
+		if err != nil {
+			return configError(cmd, fmt.Errorf("generating units: %w", err))
+		}
+
+		if printOnly {
+			for i, u := range units {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("# %s\n%s", u.Name, u.Content)
+			}
+			return nil
+		}
+
+		dir, err := service.UserUnitDir()
+		if err != nil {
+			return fmt.Errorf("locating unit directory: %w", err)
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		paths, err := service.Install(dir, units, force)
+		if errors.Is(err, os.ErrExist) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("installing units: %w; use --force to overwrite", err)
+		}
+		if err != nil {
+			return fmt.Errorf("installing units: %w", err)
+		}
+
+		for _, p := range paths {
+			logger("Wrote %s", p)
+		}
+		logger("Enable with: systemctl --user daemon-reload && systemctl --user enable --now %s",
+			units[len(units)-1].Name)
+		return nil
+	},
+}
+
+var uninstallServiceCmd = &cobra.Command{
+	Use:   "uninstall-service",
+	Short: "Remove systemd user units created by install-service",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		if !service.ValidName(name) {
+			return configError(cmd, fmt.Errorf("invalid unit name %q", name))
+		}
+
+		dir, err := service.UserUnitDir()
+		if err != nil {
+			return fmt.Errorf("locating unit directory: %w", err)
+		}
+
+		removed, err := service.Uninstall(dir, name)
+		if err != nil {
+			return fmt.Errorf("uninstalling units: %w", err)
+		}
+
+		if len(removed) == 0 {
+			logger("No units named %q found in %s.", name, dir)
+			return nil
+		}
+
+		for _, p := range removed {
+			logger("Removed %s", p)
+		}
+		logger("Stop any running units first with: systemctl --user disable --now %s.timer %s.path", name, name)
+		return nil
+	},
+}
+
+func init() {
+	installServiceCmd.Flags().String("name", service.DefaultName, "base name for the generated unit files")
+	installServiceCmd.Flags().String("mode", service.ModeTimer, "trigger mode: timer | path")
+	installServiceCmd.Flags().String("schedule", service.DefaultSchedule, "OnCalendar expression used in timer mode")
+	installServiceCmd.Flags().String("binary", "", "path to the forg binary (defaults to the running executable)")
+	installServiceCmd.Flags().Bool("print", false, "print the units to stdout instead of installing them")
+	installServiceCmd.Flags().Bool("force", false, "overwrite units that already exist")
+	installServiceCmd.Flags().BoolP("recursive", "r", false, "scan directories recursively when the service runs")
+	installServiceCmd.Flags().Bool("include-hidden", false, "include hidden files and directories when the service runs")
+	uninstallServiceCmd.Flags().String("name", service.DefaultName, "base name of the unit files to remove")
+	rootCmd.AddCommand(installServiceCmd)
+	rootCmd.AddCommand(uninstallServiceCmd)
+}
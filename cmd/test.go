@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/fixture"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [test-file]...",
+	Short: "Check the config's rules against test cases of virtual files",
+	Long: "Test evaluates each case in the test files against the config's rules\n" +
+		"and reports every file whose winning rule is not the expected one.\n" +
+		"Files are virtual: nothing is read from or written to the source.\n" +
+		"With several sources, a case's source field picks the source its file\n" +
+		"is placed in and whose rules it is checked against; the default is\n" +
+		"the first source.\n" +
+		"Without arguments the cases are read from " + internal.DefaultTestFile + ".",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+
+		if len(args) == 0 {
+			args = []string{internal.DefaultTestFile}
+		}
+
+		passed, failed := 0, 0
+		for _, path := range args {
+			suite, err := fixture.Load(path)
+			if err != nil {
+				return configError(cmd, err)
+			}
+			results, err := fixture.Run(suite, cfg)
+			if err != nil {
+				return configError(cmd, fmt.Errorf("%s: %w", path, err))
+			}
+
+			for _, r := range results {
+				if r.Passed {
+					passed++
+					if verbose {
+						logger("PASS %s: %s", r.Case.File, r.Got)
+					}
+					continue
+				}
+				failed++
+				fmt.Printf("FAIL %s: expected %s, got %s\n", r.Case.File, r.Case.Expect, r.Got)
+			}
+		}
+
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d case(s) failed", failed, passed+failed)
+		}
+		if !quiet {
+			fmt.Printf("ok: %d case(s) passed\n", passed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+}
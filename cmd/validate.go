@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+var validateStrict bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file for errors and likely mistakes",
+	Long: "Validate loads the config file without scanning or moving any files.\n" +
+		"It reports errors, then warns about rules that can never match,\n" +
+		"duplicate rule names, and destinations that are missing, unwritable\n" +
+		"or inside the source directory.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("loading config: %w", err))
+		}
+
+		findings, err := lint.Check(cfg)
+		if err != nil {
+			return configError(cmd, fmt.Errorf("checking config: %w", err))
+		}
+		warnings := append(slices.Clone(cfg.Warnings), findings...)
+
+		// Warnings are the point of validating, so they are printed even
+		// in quiet mode, like the errors of a run.
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+
+		if validateStrict && len(warnings) > 0 {
+			return configError(cmd, fmt.Errorf("%d warning(s) in %s", len(warnings), cfgFile))
+		}
+
+		if !quiet {
+			fmt.Printf("%s is valid: %d rule(s), %d warning(s)\n", cfgFile, len(cfg.AllRules()), len(warnings))
+		}
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "treat warnings as errors")
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,155 @@
+// Package cache persists the rule decision made for each scanned file so
+// that repeated runs only evaluate files that are new or have changed.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// Entry is the cached state of a single file.
+type Entry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Inode   uint64    `json:"inode,omitempty"`
+	// Rule is the index of the rule that matched the file, or -1 when no
+	// rule matched.
+	Rule int `json:"rule"`
+	// Expires is when an age criterion could change the decision. The zero
+	// time means the decision only changes with the file or the config.
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Index maps file paths to their cached entries for one source directory
+// and configuration.
+type Index struct {
+	ConfigHash string           `json:"config_hash"`
+	Entries    map[string]Entry `json:"entries"`
+
+	// next collects the entries seen during the current run; Save writes
+	// only these so files that have gone are dropped.
+	next map[string]Entry
+}
+
+// Path returns the cache file used for source (~/.forg/cache/<digest>.json).
+func Path(source string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("resolving source path: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := hex.EncodeToString(sum[:8]) + ".json"
+	return filepath.Join(home, internal.UndoLogDir, internal.CacheDir, name), nil
+}
+
+// Load reads the index at path. A missing file, or one written for a
+// different configHash, yields an empty index so every file is evaluated.
+func Load(path, configHash string) (*Index, error) {
+	idx := &Index{
+		ConfigHash: configHash,
+		Entries:    make(map[string]Entry),
+		next:       make(map[string]Entry),
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is controlled by caller
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("reading cache from %s: %w", path, err)
+	}
+
+	var stored Index
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("parsing cache from %s: %w", path, err)
+	}
+	if stored.ConfigHash == configHash && stored.Entries != nil {
+		idx.Entries = stored.Entries
+	}
+	return idx, nil
+}
+
+// Lookup returns the rule index cached for f at now. It reports false when
+// f is not cached, has changed since it was cached, or the cached decision
+// has expired.
+func (idx *Index) Lookup(f scanner.FileInfo, now time.Time) (int, bool) {
+	e, ok := idx.Entries[f.Path]
+	if !ok || e.Size != f.Size || !e.ModTime.Equal(f.ModTime) || e.Inode != f.Inode {
+		return 0, false
+	}
+	if !e.Expires.IsZero() && !now.Before(e.Expires) {
+		return 0, false
+	}
+	idx.next[f.Path] = e
+	return e.Rule, true
+}
+
+// Record stores the decision for f. expires is when the decision could
+// change on its own, or the zero time if it cannot.
+func (idx *Index) Record(f scanner.FileInfo, rule int, expires time.Time) {
+	idx.next[f.Path] = Entry{
+		Size:    f.Size,
+		ModTime: f.ModTime,
+		Inode:   f.Inode,
+		Rule:    rule,
+		Expires: expires,
+	}
+}
+
+// Save writes the entries looked up or recorded since Load to path,
+// replacing the previous file atomically.
+func (idx *Index) Save(path string) error {
+	return idx.write(path, idx.next)
+}
+
+// SavePartial is Save for a run that stopped before every file was seen: the
+// loaded entries of files not seen are kept alongside the new ones, so the
+// next run only evaluates what this one did not reach.
+func (idx *Index) SavePartial(path string) error {
+	entries := maps.Clone(idx.Entries)
+	maps.Copy(entries, idx.next)
+	return idx.write(path, entries)
+}
+
+// write stores entries at path, replacing the previous file atomically.
+func (idx *Index) write(path string, entries map[string]Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), internal.DefaultDirPerms); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(Index{ConfigHash: idx.ConfigHash, Entries: entries})
+	if err != nil {
+		return fmt.Errorf("marshaling cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing cache to %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache to %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing cache at %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	idx, err := Load(filepath.Join(t.TempDir(), "missing.json"), "hash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(idx.Entries) != 0 {
+		t.Errorf("expected an empty index, got %v", idx.Entries)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("writing cache: %v", err)
+	}
+	if _, err := Load(path, "hash"); err == nil {
+		t.Fatal("expected error for corrupt cache, got nil")
+	}
+}
+
+func TestIndex_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "index.json")
+	now := time.Now()
+	kept := scanner.FileInfo{Path: "/src/a.txt", Size: 10, ModTime: now.Add(-time.Hour), Inode: 7}
+	gone := scanner.FileInfo{Path: "/src/gone.txt", Size: 1, ModTime: now}
+
+	idx, err := Load(path, "v1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	idx.Record(kept, 2, time.Time{})
+	if err := idx.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	t.Run("same config reuses entries", func(t *testing.T) {
+		idx, err := Load(path, "v1")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		rule, ok := idx.Lookup(kept, now)
+		if !ok || rule != 2 {
+			t.Errorf("Lookup = %d, %v; want 2, true", rule, ok)
+		}
+		if _, ok := idx.Lookup(gone, now); ok {
+			t.Error("expected a miss for an uncached file")
+		}
+	})
+
+	t.Run("changed config discards entries", func(t *testing.T) {
+		idx, err := Load(path, "v2")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if _, ok := idx.Lookup(kept, now); ok {
+			t.Error("expected a miss after the config hash changed")
+		}
+	})
+
+	t.Run("unseen entries are dropped", func(t *testing.T) {
+		idx, err := Load(path, "v1")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		idx.Record(gone, -1, time.Time{})
+		if err := idx.Save(path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		idx, err = Load(path, "v1")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if _, ok := idx.Entries[kept.Path]; ok {
+			t.Errorf("expected %s to be dropped", kept.Path)
+		}
+		if _, ok := idx.Entries[gone.Path]; !ok {
+			t.Errorf("expected %s to be kept", gone.Path)
+		}
+	})
+	t.Run("partial save keeps unseen entries", func(t *testing.T) {
+		idx, err := Load(path, "v1")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		idx.Record(kept, 1, time.Time{})
+		if err := idx.SavePartial(path); err != nil {
+			t.Fatalf("SavePartial: %v", err)
+		}
+
+		idx, err = Load(path, "v1")
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if e, ok := idx.Entries[kept.Path]; !ok || e.Rule != 1 {
+			t.Errorf("entry for %s = %+v, %v; want the new decision", kept.Path, e, ok)
+		}
+		if _, ok := idx.Entries[gone.Path]; !ok {
+			t.Errorf("expected %s to be kept", gone.Path)
+		}
+	})
+}
+
+func TestIndex_Lookup(t *testing.T) {
+	now := time.Now()
+	mtime := now.Add(-time.Hour)
+	f := scanner.FileInfo{Path: "/src/a.txt", Size: 10, ModTime: mtime, Inode: 7}
+
+	tests := []struct {
+		name    string
+		file    scanner.FileInfo
+		expires time.Time
+		want    bool
+	}{
+		{name: "unchanged", file: f, want: true},
+		{name: "size changed", file: scanner.FileInfo{Path: f.Path, Size: 11, ModTime: mtime, Inode: 7}},
+		{name: "mtime changed", file: scanner.FileInfo{Path: f.Path, Size: 10, ModTime: now, Inode: 7}},
+		{name: "inode changed", file: scanner.FileInfo{Path: f.Path, Size: 10, ModTime: mtime, Inode: 8}},
+		{name: "not yet expired", file: f, expires: now.Add(time.Minute), want: true},
+		{name: "expired", file: f, expires: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := Load(filepath.Join(t.TempDir(), "missing.json"), "hash")
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			idx.Entries[f.Path] = Entry{Size: f.Size, ModTime: f.ModTime, Inode: f.Inode, Rule: 0, Expires: tt.expires}
+
+			if _, got := idx.Lookup(tt.file, now); got != tt.want {
+				t.Errorf("Lookup() hit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package category defines the built-in catalog of file categories, each a
+// named set of extensions with a conventional destination folder.
+package category
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/devaloi/forg/internal"
+)
+
+// Category is a named group of file extensions.
+type Category struct {
+	Name        string
+	Description string
+	// Extensions are lower-case and include the leading dot.
+	Extensions []string
+	// Folder is the suggested destination for the category's files.
+	Folder string
+	// Origin records where the category was defined: built in, added by a
+	// config, or a built-in one that a config extended or overrode.
+	Origin string
+}
+
+// Catalog is an ordered set of categories with unique names.
+type Catalog []Category
+
+// builtin is the catalog, in the order categories are listed and generated.
+// An extension belongs to at most one category.
+var builtin = []Category{
+	{
+		Name:        "images",
+		Description: "Photos, graphics and camera raw files",
+		Extensions:  []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".tif", ".tiff", ".svg", ".heic", ".heif", ".avif", ".raw", ".cr2", ".nef", ".arw", ".dng"},
+		Folder:      "~/Pictures",
+	},
+	{
+		Name:        "videos",
+		Description: "Video files",
+		Extensions:  []string{".mp4", ".mov", ".avi", ".mkv", ".webm", ".wmv", ".flv", ".m4v", ".mpg", ".mpeg"},
+		Folder:      "~/Videos",
+	},
+	{
+		Name:        "audio",
+		Description: "Music and other audio recordings",
+		Extensions:  []string{".mp3", ".wav", ".flac", ".aac", ".ogg", ".oga", ".opus", ".m4a", ".wma", ".aiff"},
+		Folder:      "~/Music",
+	},
+	{
+		Name:        "documents",
+		Description: "Text documents and PDFs",
+		Extensions:  []string{".pdf", ".doc", ".docx", ".odt", ".rtf", ".txt", ".md", ".tex", ".pages"},
+		Folder:      "~/Documents",
+	},
+	{
+		Name:        "spreadsheets",
+		Description: "Spreadsheets and tabular data",
+		Extensions:  []string{".xls", ".xlsx", ".ods", ".csv", ".tsv", ".numbers"},
+		Folder:      "~/Documents/Spreadsheets",
+	},
+	{
+		Name:        "presentations",
+		Description: "Slide decks",
+		Extensions:  []string{".ppt", ".pptx", ".odp", ".key"},
+		Folder:      "~/Documents/Presentations",
+	},
+	{
+		Name:        "ebooks",
+		Description: "E-books and comics",
+		Extensions:  []string{".epub", ".mobi", ".azw", ".azw3", ".fb2", ".djvu", ".cbz", ".cbr"},
+		Folder:      "~/Books",
+	},
+	{
+		Name:        "archives",
+		Description: "Compressed archives",
+		Extensions:  []string{".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".7z", ".rar"},
+		Folder:      "~/Archives",
+	},
+	{
+		Name:        "installers",
+		Description: "Installers, packages and disk images",
+		Extensions:  []string{".dmg", ".pkg", ".exe", ".msi", ".deb", ".rpm", ".appimage", ".apk", ".iso"},
+		Folder:      "~/Installers",
+	},
+	{
+		Name:        "code",
+		Description: "Source code, scripts and data formats",
+		Extensions:  []string{".go", ".py", ".js", ".ts", ".rs", ".java", ".c", ".h", ".cpp", ".rb", ".php", ".sh", ".sql", ".html", ".css", ".json", ".yaml", ".yml", ".toml", ".xml", ".ipynb"},
+		Folder:      "~/Code",
+	},
+	{
+		Name:        "fonts",
+		Description: "Font files",
+		Extensions:  []string{".ttf", ".otf", ".woff", ".woff2"},
+		Folder:      "~/Fonts",
+	},
+}
+
+// Builtin returns the built-in categories in catalog order. The result may be
+// modified by the caller.
+func Builtin() Catalog {
+	out := make(Catalog, len(builtin))
+	for i, c := range builtin {
+		out[i] = c.clone()
+		out[i].Origin = internal.OriginBuiltin
+	}
+	return out
+}
+
+// Lookup returns the built-in category called name.
+func Lookup(name string) (Category, bool) {
+	return Builtin().Lookup(name)
+}
+
+// ForExtension returns the built-in category containing ext, which is
+// compared case-insensitively and must include the leading dot.
+func ForExtension(ext string) (Category, bool) {
+	return Builtin().ForExtension(ext)
+}
+
+// Lookup returns the category in c called name.
+func (c Catalog) Lookup(name string) (Category, bool) {
+	for _, cat := range c {
+		if cat.Name == name {
+			return cat.clone(), true
+		}
+	}
+	return Category{}, false
+}
+
+// ForExtension returns the first category in c containing ext, which is
+// compared case-insensitively and must include the leading dot.
+func (c Catalog) ForExtension(ext string) (Category, bool) {
+	ext = strings.ToLower(ext)
+	for _, cat := range c {
+		if slices.Contains(cat.Extensions, ext) {
+			return cat.clone(), true
+		}
+	}
+	return Category{}, false
+}
+
+// Names returns the names of the categories in c, in catalog order.
+func (c Catalog) Names() []string {
+	names := make([]string, len(c))
+	for i, cat := range c {
+		names[i] = cat.Name
+	}
+	return names
+}
+
+// clone returns a copy of c that shares no slices with it.
+func (c Category) clone() Category {
+	c.Extensions = slices.Clone(c.Extensions)
+	return c
+}
@@ -0,0 +1,62 @@
+package category
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltin_ExtensionsAreUniqueAndNormalized(t *testing.T) {
+	seen := make(map[string]string)
+	for _, c := range Builtin() {
+		if c.Name == "" || c.Folder == "" || len(c.Extensions) == 0 {
+			t.Errorf("category %+v is incomplete", c)
+		}
+		for _, ext := range c.Extensions {
+			if !strings.HasPrefix(ext, ".") || strings.ToLower(ext) != ext {
+				t.Errorf("category %s: extension %q must be lower-case with a leading dot", c.Name, ext)
+			}
+			if other, ok := seen[ext]; ok {
+				t.Errorf("extension %s is in both %s and %s", ext, other, c.Name)
+			}
+			seen[ext] = c.Name
+		}
+	}
+}
+
+func TestForExtension(t *testing.T) {
+	tests := []struct {
+		ext  string
+		want string
+	}{
+		{".jpg", "images"},
+		{".JPG", "images"},
+		{".mkv", "videos"},
+		{".epub", "ebooks"},
+		{".csv", "spreadsheets"},
+		{".unknown", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := ForExtension(tt.ext)
+		if ok != (tt.want != "") || got.Name != tt.want {
+			t.Errorf("ForExtension(%q) = %q, %v; want %q", tt.ext, got.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup("audio")
+	if !ok || c.Folder != "~/Music" {
+		t.Fatalf("Lookup(audio) = %+v, %v", c, ok)
+	}
+
+	c.Extensions[0] = ".changed"
+	if again, _ := Lookup("audio"); again.Extensions[0] == ".changed" {
+		t.Error("Lookup returned a category sharing the catalog's extensions")
+	}
+
+	if _, ok := Lookup("nope"); ok {
+		t.Error("Lookup(nope) found a category")
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/category"
+	"gopkg.in/yaml.v3"
+)
+
+// CategoryConfig defines a category, or changes a built-in one of the same
+// name.
+type CategoryConfig struct {
+	Description string `yaml:"description,omitempty"`
+	// Extensions replaces the extensions of a built-in category.
+	Extensions []string `yaml:"extensions,omitempty"`
+	// Add appends extensions to those of a built-in category, or to
+	// Extensions.
+	Add []string `yaml:"add,omitempty"`
+	// Folder is the destination of rules that use the category as a preset.
+	Folder string `yaml:"folder,omitempty"`
+}
+
+// Catalog returns the categories rules can refer to: the built-in ones, as
+// changed by the config, followed by those the config adds in name order.
+func (c *Config) Catalog() category.Catalog {
+	catalog := category.Builtin()
+	for i := range catalog {
+		if def, ok := c.Categories[catalog[i].Name]; ok {
+			def.apply(&catalog[i])
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Categories)) {
+		if _, ok := catalog.Lookup(name); ok {
+			continue
+		}
+		cat := category.Category{Name: name, Origin: internal.OriginConfig}
+		c.Categories[name].apply(&cat)
+		catalog = append(catalog, cat)
+	}
+	return catalog
+}
+
+// apply changes cat as d describes.
+func (d CategoryConfig) apply(cat *category.Category) {
+	if d.Description != "" {
+		cat.Description = d.Description
+	}
+	if d.Folder != "" {
+		cat.Folder = d.Folder
+	}
+	if len(d.Extensions) > 0 {
+		cat.Extensions = addExtensions(nil, d.Extensions)
+		if cat.Origin == internal.OriginBuiltin {
+			cat.Origin = internal.OriginOverridden
+		}
+	}
+	if len(d.Add) > 0 {
+		cat.Extensions = addExtensions(cat.Extensions, d.Add)
+		if cat.Origin == internal.OriginBuiltin {
+			cat.Origin = internal.OriginExtended
+		}
+	}
+}
+
+// addExtensions appends the lower-cased extensions in add that dst does not
+// already contain.
+func addExtensions(dst, add []string) []string {
+	for _, ext := range add {
+		ext = strings.ToLower(ext)
+		if !slices.Contains(dst, ext) {
+			dst = append(dst, ext)
+		}
+	}
+	return dst
+}
+
+// validateCategories checks the categories block, whose node is n.
+func validateCategories(defs map[string]CategoryConfig, n *yaml.Node, p *problems) {
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		def := defs[name]
+		node := valueNode(n, name)
+
+		if _, builtin := category.Lookup(name); !builtin && len(def.Extensions) == 0 && len(def.Add) == 0 {
+			p.addf(node, "category %q: extensions are required for a category that is not built in", name)
+		}
+
+		checkExtensions(name, def.Extensions, valueNode(node, "extensions"), p)
+		checkExtensions(name, def.Add, valueNode(node, "add"), p)
+	}
+}
+
+// checkExtensions records each extension in list, whose node is n, that
+// does not start with a dot.
+func checkExtensions(name string, list []string, n *yaml.Node, p *problems) {
+	for i, ext := range list {
+		if len(ext) < 2 || ext[0] != '.' {
+			p.addf(itemNode(n, i), "category %q: extension %q must start with a dot", name, ext)
+		}
+	}
+}
+
+// resolveCategory fills in a rule from its preset and adds the extensions of
+// its match category, recording unknown category names against node, the
+// rule's entry in the rules list.
+func resolveCategory(index int, rule *RuleConfig, catalog category.Catalog, node *yaml.Node, p *problems) {
+	if rule.Preset != "" {
+		cat, ok := catalog.Lookup(rule.Preset)
+		if !ok {
+			p.addf(valueNode(node, "preset"), "%s: %s", ruleLabel(index, *rule), unknownCategory("preset", rule.Preset, catalog))
+		} else {
+			if rule.Name == "" {
+				rule.Name = cat.Name
+			}
+			if rule.Destination == "" {
+				rule.Destination = cat.Folder
+			}
+			if rule.Match.Category == "" {
+				rule.Match.Category = cat.Name
+			}
+		}
+	}
+
+	if rule.Match.Category == "" {
+		return
+	}
+	cat, ok := catalog.Lookup(rule.Match.Category)
+	if !ok {
+		p.addf(valueNode(valueNode(node, "match"), "category"), "%s: %s", ruleLabel(index, *rule), unknownCategory("category", rule.Match.Category, catalog))
+		return
+	}
+	rule.Match.Extensions = addExtensions(rule.Match.Extensions, cat.Extensions)
+}
+
+// unknownCategory describes a reference to a category missing from catalog,
+// suggesting the closest name.
+func unknownCategory(what, name string, catalog category.Catalog) string {
+	msg := fmt.Sprintf("unknown %s %q", what, name)
+	if s := suggest(name, catalog.Names()); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	return msg
+}
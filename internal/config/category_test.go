@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/category"
+)
+
+func TestParse_Categories(t *testing.T) {
+	srcDir := t.TempDir()
+	yaml := fmt.Sprintf(`source: %s
+categories:
+  images:
+    add: [.JXL]
+  audio:
+    extensions: [.mp3]
+  scans:
+    description: Scanned paperwork
+    extensions: [.tiff, .pdf]
+    folder: ~/Scans
+rules:
+  - preset: ebooks
+  - name: photos
+    match:
+      category: images
+      extensions: [.psd]
+    destination: ~/Photos
+  - preset: audio
+    destination: ~/Podcasts
+  - name: paper
+    preset: scans
+    match:
+      min_size: 1KB
+`, srcDir)
+
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	ebooks, _ := category.Lookup("ebooks")
+	tests := []struct {
+		rule        RuleConfig
+		name        string
+		destination string
+		category    string
+		extensions  []string
+	}{
+		{cfg.Rules[0], "ebooks", "~/Books", "ebooks", ebooks.Extensions},
+		{cfg.Rules[2], "audio", "~/Podcasts", "audio", []string{".mp3"}},
+		{cfg.Rules[3], "paper", "~/Scans", "scans", []string{".tiff", ".pdf"}},
+	}
+	for _, tt := range tests {
+		r := tt.rule
+		if r.Name != tt.name || r.Destination != tt.destination || r.Match.Category != tt.category || !slices.Equal(r.Match.Extensions, tt.extensions) {
+			t.Errorf("rule = %+v, want name %s, destination %s, category %s, extensions %v", r, tt.name, tt.destination, tt.category, tt.extensions)
+		}
+	}
+
+	photos := cfg.Rules[1].Match.Extensions
+	if photos[0] != ".psd" || !slices.Contains(photos, ".jpg") || !slices.Contains(photos, ".jxl") {
+		t.Errorf("photos extensions = %v, want .psd followed by the extended images category", photos)
+	}
+	if cfg.Rules[3].Match.MinSize != "1KB" {
+		t.Errorf("preset rule lost its own criteria: %+v", cfg.Rules[3].Match)
+	}
+}
+
+func TestConfig_Catalog(t *testing.T) {
+	cfg := &Config{Categories: map[string]CategoryConfig{
+		"images": {Add: []string{".jxl"}},
+		"audio":  {Extensions: []string{".mp3"}, Folder: "~/Audio"},
+		"zines":  {Extensions: []string{".cbz"}},
+		"fax":    {Extensions: []string{".g3"}},
+	}}
+
+	catalog := cfg.Catalog()
+	builtin := category.Builtin()
+	if got, want := len(catalog), len(builtin)+2; got != want {
+		t.Fatalf("got %d categories, want %d", got, want)
+	}
+	if names := catalog.Names(); names[len(names)-2] != "fax" || names[len(names)-1] != "zines" {
+		t.Errorf("added categories = %v, want fax and zines last in name order", names[len(builtin):])
+	}
+
+	tests := []struct {
+		name   string
+		origin string
+		folder string
+	}{
+		{"images", internal.OriginExtended, "~/Pictures"},
+		{"audio", internal.OriginOverridden, "~/Audio"},
+		{"videos", internal.OriginBuiltin, "~/Videos"},
+		{"zines", internal.OriginConfig, ""},
+	}
+	for _, tt := range tests {
+		c, ok := catalog.Lookup(tt.name)
+		if !ok || c.Origin != tt.origin || c.Folder != tt.folder {
+			t.Errorf("Lookup(%s) = %+v, want origin %s and folder %q", tt.name, c, tt.origin, tt.folder)
+		}
+	}
+
+	if c, _ := catalog.ForExtension(".cbz"); c.Name != "ebooks" {
+		t.Errorf("ForExtension(.cbz) = %s, want the built-in ebooks to take precedence", c.Name)
+	}
+}
+
+func TestParse_CategoryErrors(t *testing.T) {
+	srcDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		yaml      string
+		wantError string
+	}{
+		{
+			name:      "unknown match category",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: pics\n    match:\n      category: imges\n    destination: /tmp/out\n", srcDir),
+			wantError: `line 5, column 17: rule "pics": unknown category "imges" (did you mean "images"?)`,
+		},
+		{
+			name:      "unknown preset",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - preset: ebook\n", srcDir),
+			wantError: `line 3, column 13: rule 0: unknown preset "ebook" (did you mean "ebooks"?)`,
+		},
+		{
+			name:      "new category without extensions",
+			yaml:      fmt.Sprintf("source: %s\ncategories:\n  scans:\n    folder: ~/Scans\nrules:\n  - preset: scans\n", srcDir),
+			wantError: `category "scans": extensions are required`,
+		},
+		{
+			name:      "extension without dot",
+			yaml:      fmt.Sprintf("source: %s\ncategories:\n  images:\n    add: [jxl]\nrules:\n  - preset: images\n", srcDir),
+			wantError: `line 4, column 11: category "images": extension "jxl" must start with a dot`,
+		},
+		{
+			name:      "preset without folder",
+			yaml:      fmt.Sprintf("source: %s\ncategories:\n  scans:\n    extensions: [.tiff]\nrules:\n  - preset: scans\n", srcDir),
+			wantError: `rule "scans": destination is required`,
+		},
+		{
+			name:      "misspelled category key",
+			yaml:      fmt.Sprintf("source: %s\ncategories:\n  images:\n    extentions: [.jxl]\nrules:\n  - preset: images\n", srcDir),
+			wantError: `unknown key "extentions" in categories (did you mean "extensions"?)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("Parse() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
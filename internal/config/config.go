@@ -2,6 +2,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,14 +12,40 @@ import (
 	"strings"
 
 	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/ignore"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the top-level forg configuration.
 type Config struct {
-	Source   string       `yaml:"source"`
-	Conflict string       `yaml:"conflict"`
-	Rules    []RuleConfig `yaml:"rules"`
+	// Include lists config files, or globs of them, to merge beneath this
+	// one. Relative paths are resolved against the including file's
+	// directory.
+	Include []string `yaml:"include,omitempty"`
+	// Vars defines variables that ${NAME} references in the source, rule
+	// destinations and category folders expand to.
+	Vars   map[string]string `yaml:"vars,omitempty"`
+	Source string            `yaml:"source,omitempty"`
+	// Sources lists several directories to organize, each with its own
+	// scan options and rules, in place of Source.
+	Sources  []SourceConfig `yaml:"sources,omitempty"`
+	Conflict string         `yaml:"conflict,omitempty"`
+	Exclude  []string       `yaml:"exclude,omitempty"`
+	Opaque   []string       `yaml:"opaque,omitempty"`
+	MinDepth int            `yaml:"min_depth,omitempty"`
+	MaxDepth int            `yaml:"max_depth,omitempty"`
+	Symlinks string         `yaml:"symlinks,omitempty"`
+	Rules    []RuleConfig   `yaml:"rules"`
+
+	// Categories defines new categories and extends or overrides built-in
+	// ones, keyed by category name.
+	Categories map[string]CategoryConfig `yaml:"categories,omitempty"`
+
+	// Warnings holds non-fatal problems found during validation.
+	Warnings []string `yaml:"-"`
+	// Files lists the included files that were merged, in merge order,
+	// followed by the config file itself when it was loaded from disk.
+	Files []string `yaml:"-"`
 }
 
 // RuleConfig represents a single organization rule.
@@ -25,16 +53,29 @@ type RuleConfig struct {
 	Name        string      `yaml:"name"`
 	Match       MatchConfig `yaml:"match"`
 	Destination string      `yaml:"destination"`
+	// Preset fills in the rule from a category: its name, destination and
+	// extensions, unless the rule sets them itself.
+	Preset string `yaml:"preset,omitempty"`
+
+	// Origin is the file the rule was defined in, or empty for a config
+	// parsed from memory.
+	Origin string `yaml:"-"`
 }
 
 // MatchConfig defines the criteria for matching files in a rule.
 type MatchConfig struct {
+	// Category adds the extensions of a category to Extensions.
+	Category   string   `yaml:"category,omitempty"`
 	Extensions []string `yaml:"extensions,omitempty"`
 	Pattern    string   `yaml:"pattern,omitempty"`
 	MinSize    string   `yaml:"min_size,omitempty"`
 	MaxSize    string   `yaml:"max_size,omitempty"`
 	OlderThan  string   `yaml:"older_than,omitempty"`
 	NewerThan  string   `yaml:"newer_than,omitempty"`
+	Depth      string   `yaml:"depth,omitempty"`
+
+	BrokenSymlink bool     `yaml:"broken_symlink,omitempty"`
+	Types         []string `yaml:"types,omitempty"`
 }
 
 // sizePattern matches size strings like "100MB", "1.5GB", "500KB".
@@ -43,6 +84,9 @@ var sizePattern = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*(B|KB|MB|GB|TB)$`)
 // durationPattern matches duration strings like "30d", "2w", "6m", "1y".
 var durationPattern = regexp.MustCompile(`(?i)^(\d+)\s*(d|w|m|y)$`)
 
+// depthPattern matches depth strings like "2", "1-3", and "2+".
+var depthPattern = regexp.MustCompile(`^(\d+)(?:\s*(-)\s*(\d+)|\s*(\+))?$`)
+
 // sizeMultipliers maps size unit suffixes to their byte multipliers.
 var sizeMultipliers = map[string]int64{
 	"b":  1,
@@ -61,6 +105,7 @@ var durationMultipliers = map[string]int64{
 }
 
 // Load reads and parses a .forg.yaml configuration file from the given path.
+// Validation problems are returned as Errors positioned in that file.
 func Load(path string) (*Config, error) {
 	expanded, err := ExpandPath(path)
 	if err != nil {
@@ -72,7 +117,7 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config file %s: %w", expanded, err)
 	}
 
-	cfg, err := Parse(data)
+	cfg, err := parse(expanded, data)
 	if err != nil {
 		return nil, fmt.Errorf("parsing config file %s: %w", expanded, err)
 	}
@@ -80,108 +125,267 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
-// Parse unmarshals YAML data into a Config and validates it.
+// Parse unmarshals YAML data into a Config and validates it. Unknown keys are
+// rejected, and every problem found is returned together as Errors.
 func Parse(data []byte) (*Config, error) {
+	return parse("", data)
+}
+
+// parse decodes data, merges in the files it includes and validates the
+// result, attributing problems to file or the included file they are in.
+func parse(file string, data []byte) (*Config, error) {
+	p := &problems{file: file, files: make(map[*yaml.Node]string)}
+	l := &loader{root: p}
+	if file != "" {
+		if abs, err := filepath.Abs(file); err == nil {
+			l.stack = []string{abs}
+		}
+	}
+
+	doc, err := l.load(file, data, p)
+	if err != nil {
+		return nil, err
+	}
+
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("unmarshaling YAML: %w", err)
+	if doc != nil {
+		// Type errors were recorded as each file was loaded.
+		_ = doc.Decode(&cfg)
+	}
+
+	cfg.Files = l.files
+	if file != "" {
+		cfg.Files = append(cfg.Files, file)
+	}
+	rules := valueNode(doc, "rules")
+	for i := range cfg.Rules {
+		cfg.Rules[i].Origin = p.fileOf(itemNode(rules, i))
+	}
+	sources := valueNode(doc, "sources")
+	for i, src := range cfg.Sources {
+		srcRules := valueNode(itemNode(sources, i), "rules")
+		for j := range src.Rules {
+			src.Rules[j].Origin = p.fileOf(itemNode(srcRules, j))
+		}
 	}
 
-	if err := validate(&cfg); err != nil {
+	validate(&cfg, doc, p)
+	if err := p.err(); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
 	}
 
 	return &cfg, nil
 }
 
-// validate checks that the config is well-formed.
-func validate(cfg *Config) error {
-	if cfg.Source == "" {
-		return fmt.Errorf("source directory is required")
+// validate checks that the config is well-formed, recording each problem
+// against the node in doc it came from.
+func validate(cfg *Config, doc *yaml.Node, p *problems) {
+	expandVars(cfg, doc, p)
+
+	var srcExpanded string
+	if len(cfg.Sources) == 0 {
+		srcExpanded = checkSource(cfg.Source, valueNode(doc, "source"), p)
+	} else if cfg.Source != "" {
+		p.addf(valueNode(doc, "source"), "source and sources cannot both be set; list every directory under sources")
 	}
 
-	srcExpanded, err := ExpandPath(cfg.Source)
-	if err != nil {
-		return fmt.Errorf("expanding source path: %w", err)
+	if cfg.Conflict != "" && !internal.ValidConflictStrategy(cfg.Conflict) {
+		p.addf(valueNode(doc, "conflict"), "invalid conflict strategy %q: must be skip, rename, or overwrite", cfg.Conflict)
 	}
 
-	info, err := os.Stat(srcExpanded)
-	if err != nil {
-		return fmt.Errorf("source directory %s: %w", srcExpanded, err)
+	if cfg.Symlinks != "" && !internal.ValidSymlinkPolicy(cfg.Symlinks) {
+		p.addf(valueNode(doc, "symlinks"), "invalid symlinks policy %q: must be skip, move-link, or follow", cfg.Symlinks)
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("source path %s is not a directory", srcExpanded)
+
+	for i, pattern := range cfg.Exclude {
+		if _, err := ignore.Compile(pattern); err != nil {
+			p.addf(itemNode(valueNode(doc, "exclude"), i), "exclude: %v", err)
+		}
 	}
 
-	if cfg.Conflict != "" && !internal.ValidConflictStrategy(cfg.Conflict) {
-		return fmt.Errorf("invalid conflict strategy %q: must be skip, rename, or overwrite", cfg.Conflict)
+	for i, pattern := range cfg.Opaque {
+		if _, err := ignore.Compile(pattern); err != nil {
+			p.addf(itemNode(valueNode(doc, "opaque"), i), "opaque: %v", err)
+		}
+	}
+
+	switch {
+	case cfg.MinDepth < 0:
+		p.addf(valueNode(doc, "min_depth"), "min_depth and max_depth must not be negative")
+	case cfg.MaxDepth < 0:
+		p.addf(valueNode(doc, "max_depth"), "min_depth and max_depth must not be negative")
+	case cfg.MaxDepth > 0 && cfg.MinDepth > cfg.MaxDepth:
+		p.addf(valueNode(doc, "min_depth"), "min_depth %d is greater than max_depth %d", cfg.MinDepth, cfg.MaxDepth)
 	}
 
-	if len(cfg.Rules) == 0 {
-		return fmt.Errorf("at least one rule is required")
+	if len(cfg.Rules) == 0 && len(cfg.Sources) == 0 {
+		p.addf(valueNode(doc, "rules"), "at least one rule is required")
+		return
 	}
 
+	validateCategories(cfg.Categories, valueNode(doc, "categories"), p)
+	catalog := cfg.Catalog()
+
+	rules := valueNode(doc, "rules")
+	for i := range cfg.Rules {
+		resolveCategory(i, &cfg.Rules[i], catalog, itemNode(rules, i), p)
+	}
 	for i, rule := range cfg.Rules {
-		if err := validateRule(i, rule); err != nil {
-			return err
+		validateRule(i, rule, cfg.Symlinks, itemNode(rules, i), p)
+		if srcExpanded != "" {
+			cfg.Warnings = append(cfg.Warnings, destinationWarnings(srcExpanded, rule)...)
 		}
 	}
 
-	return nil
+	validateSources(cfg, valueNode(doc, "sources"), catalog, p)
+}
+
+// destinationWarnings reports a rule whose destination is the source itself
+// or one of its ancestors, which makes files land back where they are scanned.
+func destinationWarnings(source string, rule RuleConfig) []string {
+	dest, err := ExpandPath(rule.Destination)
+	if err != nil {
+		return nil
+	}
+
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil
+	}
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case absDest == absSource:
+		return []string{fmt.Sprintf("rule %q: destination %s is the source directory", rule.Name, dest)}
+	case IsWithin(absSource, absDest):
+		return []string{fmt.Sprintf("rule %q: destination %s contains the source directory", rule.Name, dest)}
+	default:
+		return nil
+	}
+}
+
+// IsWithin reports whether path lies strictly inside dir. Both paths should
+// be absolute and clean.
+func IsWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
-// validateRule checks that a single rule has all required fields and valid values.
-func validateRule(index int, rule RuleConfig) error {
+// validateRule checks that a single rule has all required fields and valid
+// values. symlinks is the config's symlink policy and node is the rule's
+// entry in the rules list.
+func validateRule(index int, rule RuleConfig, symlinks string, node *yaml.Node, p *problems) {
+	label := ruleLabel(index, rule)
 	if rule.Name == "" {
-		return fmt.Errorf("rule %d: name is required", index)
+		p.addf(node, "%s: name is required", label)
 	}
 
 	if rule.Destination == "" {
-		return fmt.Errorf("rule %q: destination is required", rule.Name)
+		p.addf(node, "%s: destination is required", label)
 	}
 
-	hasMatch := len(rule.Match.Extensions) > 0 ||
+	hasMatch := rule.Match.Category != "" ||
+		len(rule.Match.Extensions) > 0 ||
 		rule.Match.Pattern != "" ||
 		rule.Match.MinSize != "" ||
 		rule.Match.MaxSize != "" ||
 		rule.Match.OlderThan != "" ||
-		rule.Match.NewerThan != ""
+		rule.Match.NewerThan != "" ||
+		rule.Match.Depth != "" ||
+		rule.Match.BrokenSymlink ||
+		len(rule.Match.Types) > 0
 
+	match := valueNode(node, "match")
 	if !hasMatch {
-		return fmt.Errorf("rule %q: at least one match criterion is required", rule.Name)
+		p.addf(match, "%s: at least one match criterion is required", label)
 	}
 
 	if rule.Match.Pattern != "" {
 		if _, err := filepath.Match(rule.Match.Pattern, ""); err != nil {
-			return fmt.Errorf("rule %q: invalid pattern %q: %w", rule.Name, rule.Match.Pattern, err)
+			p.addf(valueNode(match, "pattern"), "%s: invalid pattern %q: %v", label, rule.Match.Pattern, err)
 		}
 	}
 
 	if rule.Match.MinSize != "" {
 		if _, err := ParseSize(rule.Match.MinSize); err != nil {
-			return fmt.Errorf("rule %q: invalid min_size: %w", rule.Name, err)
+			p.addf(valueNode(match, "min_size"), "%s: invalid min_size: %v", label, err)
 		}
 	}
 
 	if rule.Match.MaxSize != "" {
 		if _, err := ParseSize(rule.Match.MaxSize); err != nil {
-			return fmt.Errorf("rule %q: invalid max_size: %w", rule.Name, err)
+			p.addf(valueNode(match, "max_size"), "%s: invalid max_size: %v", label, err)
 		}
 	}
 
 	if rule.Match.OlderThan != "" {
 		if _, err := ParseDuration(rule.Match.OlderThan); err != nil {
-			return fmt.Errorf("rule %q: invalid older_than: %w", rule.Name, err)
+			p.addf(valueNode(match, "older_than"), "%s: invalid older_than: %v", label, err)
 		}
 	}
 
 	if rule.Match.NewerThan != "" {
 		if _, err := ParseDuration(rule.Match.NewerThan); err != nil {
-			return fmt.Errorf("rule %q: invalid newer_than: %w", rule.Name, err)
+			p.addf(valueNode(match, "newer_than"), "%s: invalid newer_than: %v", label, err)
+		}
+	}
+
+	if rule.Match.Depth != "" {
+		if _, _, err := ParseDepth(rule.Match.Depth); err != nil {
+			p.addf(valueNode(match, "depth"), "%s: invalid depth: %v", label, err)
+		}
+	}
+
+	for i, t := range rule.Match.Types {
+		if !internal.ValidFileType(t) {
+			p.addf(itemNode(valueNode(match, "types"), i), "%s: invalid type %q", label, t)
+		}
+	}
+
+	if rule.Match.BrokenSymlink && symlinks != internal.SymlinkMoveLink && symlinks != internal.SymlinkFollow {
+		p.addf(valueNode(match, "broken_symlink"), "%s: broken_symlink requires symlinks: move-link or follow", label)
+	}
+}
+
+// ruleLabel names a rule in messages, by its index when it has no name.
+func ruleLabel(index int, rule RuleConfig) string {
+	if rule.Name == "" {
+		return fmt.Sprintf("rule %d", index)
+	}
+	return fmt.Sprintf("rule %q", rule.Name)
+}
+
+// SpecialTypes returns every special file type requested by any rule, so the
+// scanner knows which non-regular files to collect.
+func (c *Config) SpecialTypes() []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, r := range c.AllRules() {
+		for _, t := range r.Match.Types {
+			if internal.SpecialFileType(t) && !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
 		}
 	}
+	return types
+}
 
-	return nil
+// Hash returns a digest of the effective configuration. Any change to the
+// source, scan options or rules changes the hash.
+func (c *Config) Hash() (string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("marshaling config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // ParseSize converts a human-readable size string (e.g. "100MB", "1.5GB") to bytes.
@@ -205,6 +409,21 @@ func ParseSize(s string) (int64, error) {
 	return int64(value * float64(multiplier)), nil
 }
 
+// FormatSize renders n bytes in the largest whole unit, such as "1.5 MB".
+// The result is accepted by ParseSize, up to rounding.
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // ParseDuration converts a human-readable duration string (e.g. "30d", "2w", "6m", "1y") to seconds.
 func ParseDuration(s string) (int64, error) {
 	matches := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
@@ -226,6 +445,41 @@ func ParseDuration(s string) (int64, error) {
 	return value * multiplier, nil
 }
 
+// ParseDepth converts a depth string into an inclusive range. "2" matches
+// depth 2 only, "1-3" matches depths 1 through 3, and "2+" matches depth 2 or
+// deeper, in which case max is 0. Files directly in the source have depth 1.
+func ParseDepth(s string) (minDepth, maxDepth int, err error) {
+	matches := depthPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return 0, 0, fmt.Errorf("invalid depth format %q: expected N, N-M, or N+", s)
+	}
+
+	minDepth, err = strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing depth number %q: %w", matches[1], err)
+	}
+	if minDepth < 1 {
+		return 0, 0, fmt.Errorf("invalid depth %q: depths start at 1", s)
+	}
+
+	switch {
+	case matches[2] == "-":
+		maxDepth, err = strconv.Atoi(matches[3])
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing depth number %q: %w", matches[3], err)
+		}
+		if maxDepth < minDepth {
+			return 0, 0, fmt.Errorf("invalid depth range %q: end is before start", s)
+		}
+	case matches[4] == "+":
+		maxDepth = 0
+	default:
+		maxDepth = minDepth
+	}
+
+	return minDepth, maxDepth, nil
+}
+
 // ExpandPath expands a leading ~ in a path to the user's home directory.
 func ExpandPath(path string) (string, error) {
 	if path == "" {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -58,6 +59,26 @@ func TestParseSize(t *testing.T) {
 	})
 }
 
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{104857600, "100.0 MB"},
+		{1610612736, "1.5 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.input); got != tt.expected {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
 func TestParseDuration(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		tests := []struct {
@@ -106,6 +127,43 @@ func TestParseDuration(t *testing.T) {
 	})
 }
 
+func TestParseDepth(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			input   string
+			wantMin int
+			wantMax int
+		}{
+			{"2", 2, 2},
+			{"1-3", 1, 3},
+			{"2+", 2, 0},
+			{" 3 - 4 ", 3, 4},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.input, func(t *testing.T) {
+				gotMin, gotMax, err := ParseDepth(tt.input)
+				if err != nil {
+					t.Fatalf("ParseDepth(%q) unexpected error: %v", tt.input, err)
+				}
+				if gotMin != tt.wantMin || gotMax != tt.wantMax {
+					t.Errorf("ParseDepth(%q) = %d, %d, want %d, %d", tt.input, gotMin, gotMax, tt.wantMin, tt.wantMax)
+				}
+			})
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, input := range []string{"", "0", "abc", "3-1", "-2", "1-"} {
+			t.Run(input, func(t *testing.T) {
+				if _, _, err := ParseDepth(input); err == nil {
+					t.Errorf("ParseDepth(%q) expected error, got nil", input)
+				}
+			})
+		}
+	})
+}
+
 func TestExpandPath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -223,6 +281,36 @@ func TestParse_Errors(t *testing.T) {
 			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      min_size: badsize\n    destination: /tmp/out\n", srcDir),
 			wantError: "invalid min_size",
 		},
+		{
+			name:      "invalid exclude pattern",
+			yaml:      fmt.Sprintf("source: %s\nexclude: [\"[abc\"]\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: /tmp/out\n", srcDir),
+			wantError: "exclude",
+		},
+		{
+			name:      "min_depth greater than max_depth",
+			yaml:      fmt.Sprintf("source: %s\nmin_depth: 3\nmax_depth: 2\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: /tmp/out\n", srcDir),
+			wantError: "min_depth 3 is greater than max_depth 2",
+		},
+		{
+			name:      "invalid depth",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      depth: deep\n    destination: /tmp/out\n", srcDir),
+			wantError: "invalid depth",
+		},
+		{
+			name:      "invalid symlinks policy",
+			yaml:      fmt.Sprintf("source: %s\nsymlinks: always\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: /tmp/out\n", srcDir),
+			wantError: "invalid symlinks policy",
+		},
+		{
+			name:      "broken_symlink without link policy",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      broken_symlink: true\n    destination: /tmp/out\n", srcDir),
+			wantError: "broken_symlink requires symlinks",
+		},
+		{
+			name:      "invalid file type",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      types: [pipe]\n    destination: /tmp/out\n", srcDir),
+			wantError: "invalid type",
+		},
 		{
 			name:      "invalid older_than",
 			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      older_than: badtime\n    destination: /tmp/out\n", srcDir),
@@ -243,6 +331,213 @@ func TestParse_Errors(t *testing.T) {
 	}
 }
 
+func TestParse_DestinationWarnings(t *testing.T) {
+	srcDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		destination string
+		wantWarning string
+	}{
+		{"destination is source", srcDir, "is the source directory"},
+		{"destination contains source", filepath.Dir(srcDir), "contains the source directory"},
+		{"destination inside source", filepath.Join(srcDir, "Images"), ""},
+		{"unrelated destination", "/tmp/sorted", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlData := fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: %s\n", srcDir, tt.destination)
+
+			cfg, err := Parse([]byte(yamlData))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if tt.wantWarning == "" {
+				if len(cfg.Warnings) != 0 {
+					t.Errorf("expected no warnings, got %v", cfg.Warnings)
+				}
+				return
+			}
+			if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], tt.wantWarning) {
+				t.Errorf("Warnings = %v, want one containing %q", cfg.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestConfig_SpecialTypes(t *testing.T) {
+	cfg := &Config{
+		Rules: []RuleConfig{
+			{Name: "a", Match: MatchConfig{Types: []string{"file", "fifo"}}},
+			{Name: "b", Match: MatchConfig{Types: []string{"fifo", "socket"}}},
+			{Name: "c", Match: MatchConfig{Extensions: []string{".txt"}}},
+		},
+	}
+
+	got := cfg.SpecialTypes()
+	want := []string{"fifo", "socket"}
+	if len(got) != len(want) {
+		t.Fatalf("SpecialTypes() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SpecialTypes()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfig_Hash(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Source: "/src",
+			Rules:  []RuleConfig{{Name: "a", Match: MatchConfig{Extensions: []string{".txt"}}, Destination: "/a"}},
+		}
+	}
+
+	h1, err := base().Hash()
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	h2, err := base().Hash()
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if h1 != h2 {
+		t.Errorf("identical configs hash differently: %s != %s", h1, h2)
+	}
+
+	changed := base()
+	changed.Rules[0].Match.Extensions = []string{".md"}
+	h3, err := changed.Hash()
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if h3 == h1 {
+		t.Error("expected a rule change to change the hash")
+	}
+}
+
+func TestParse_UnknownKeys(t *testing.T) {
+	srcDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		yaml      string
+		wantError string
+	}{
+		{
+			name:      "misspelled match key",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      extentions: [.jpg]\n      pattern: \"*\"\n    destination: /tmp/out\n", srcDir),
+			wantError: `line 5, column 7: unknown key "extentions" in match (did you mean "extensions"?)`,
+		},
+		{
+			name:      "hyphenated key",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      min-size: 1MB\n      pattern: \"*\"\n    destination: /tmp/out\n", srcDir),
+			wantError: `unknown key "min-size" in match (did you mean "min_size"?)`,
+		},
+		{
+			name:      "misspelled top-level key",
+			yaml:      fmt.Sprintf("source: %s\nconflcit: skip\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: /tmp/out\n", srcDir),
+			wantError: `line 2, column 1: unknown key "conflcit" (did you mean "conflict"?)`,
+		},
+		{
+			name:      "misspelled rule key",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destintion: /tmp/out\n", srcDir),
+			wantError: `unknown key "destintion" in rules (did you mean "destination"?)`,
+		},
+		{
+			name:      "unrelated key has no suggestion",
+			yaml:      fmt.Sprintf("source: %s\nwibble: true\nrules:\n  - name: test\n    match:\n      extensions: [.jpg]\n    destination: /tmp/out\n", srcDir),
+			wantError: `unknown key "wibble"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("Parse() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), tt.wantError)
+			}
+			if strings.Contains(err.Error(), "wibble") && strings.Contains(err.Error(), "did you mean") {
+				t.Errorf("Parse() error = %q, want no suggestion", err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_CollectsPositionedErrors(t *testing.T) {
+	srcDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), ".forg.yaml")
+	data := fmt.Sprintf(`source: %s
+conflict: sometimes
+rules:
+  - name: big
+    match:
+      min_size: huge
+    destination: /tmp/big
+  - match:
+      extentions: [.jpg]
+      types: [file, pipe]
+`, srcDir)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Load() expected error, got nil")
+	}
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Load() error = %v, want Errors", err)
+	}
+
+	want := []struct {
+		line, column int
+		msg          string
+	}{
+		{2, 11, "invalid conflict strategy"},
+		{6, 17, `rule "big": invalid min_size`},
+		{8, 5, "rule 1: name is required"},
+		{8, 5, "rule 1: destination is required"},
+		{9, 7, `unknown key "extentions" in match`},
+		{10, 21, `rule 1: invalid type "pipe"`},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Load() returned %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i, w := range want {
+		e := errs[i]
+		if e.File != path || e.Line != w.line || e.Column != w.column || !strings.Contains(e.Msg, w.msg) {
+			t.Errorf("errs[%d] = %s, want %s:%d:%d: ...%s...", i, e.Error(), path, w.line, w.column, w.msg)
+		}
+	}
+}
+
+func TestParse_TypeErrors(t *testing.T) {
+	srcDir := t.TempDir()
+
+	yamlData := fmt.Sprintf("source: %s\nmin_depth: deep\nrules:\n  - name: test\n    match:\n      extensions: .jpg\n    destination: /tmp/out\n", srcDir)
+	_, err := Parse([]byte(yamlData))
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() error = %v, want Errors", err)
+	}
+	if len(errs) < 2 {
+		t.Fatalf("Parse() returned %d errors, want at least 2:\n%v", len(errs), err)
+	}
+	if errs[0].Line != 2 || errs[1].Line != 6 {
+		t.Errorf("error lines = %d, %d, want 2, 6", errs[0].Line, errs[1].Line)
+	}
+}
+
 func TestLoad_FileNotFound(t *testing.T) {
 	_, err := Load("/nonexistent/path/config.yaml")
 	if err == nil {
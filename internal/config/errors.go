@@ -0,0 +1,175 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a single problem found in a config file. Line and Column locate
+// the YAML node the problem concerns; they are zero when it has no position,
+// such as a required key that is missing from an empty file.
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error formats the problem as file:line:column: message. Without a file
+// name the position is spelled out, and unknown parts are omitted.
+func (e Error) Error() string {
+	switch {
+	case e.Line == 0 && e.File == "":
+		return e.Msg
+	case e.Line == 0:
+		return e.File + ": " + e.Msg
+	case e.File == "" && e.Column == 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	case e.File == "":
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+}
+
+// Errors is every problem found while parsing a config, in file order.
+type Errors []Error
+
+// Error lists each problem on its own line.
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors:", len(e))
+	for _, err := range e {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// problems collects errors against the YAML nodes they concern, so that
+// validation can report everything wrong with a file in one pass.
+type problems struct {
+	file string
+	// files maps the nodes of every loaded file to its name, so that
+	// problems found after included files are merged name the right file.
+	files map[*yaml.Node]string
+	errs  Errors
+}
+
+// track attributes n and every node below it to p's file.
+func (p *problems) track(n *yaml.Node) {
+	if n == nil {
+		return
+	}
+	if p.files == nil {
+		p.files = make(map[*yaml.Node]string)
+	}
+	p.files[n] = p.file
+	for _, c := range n.Content {
+		p.track(c)
+	}
+}
+
+// fileOf returns the file node came from, defaulting to p's file.
+func (p *problems) fileOf(node *yaml.Node) string {
+	if file, ok := p.files[node]; ok {
+		return file
+	}
+	return p.file
+}
+
+// addf records a problem at node. A nil node records it without a position.
+func (p *problems) addf(node *yaml.Node, format string, args ...any) {
+	e := Error{File: p.fileOf(node), Msg: fmt.Sprintf(format, args...)}
+	if node != nil {
+		e.Line = node.Line
+		e.Column = node.Column
+	}
+	p.errs = append(p.errs, e)
+}
+
+// addDecode records the problems in a yaml decoding error. Type mismatches
+// are reported individually with the line yaml gives for each; anything else
+// is recorded as a single problem.
+func (p *problems) addDecode(err error) {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		p.addf(nil, "%v", err)
+		return
+	}
+	for _, msg := range typeErr.Errors {
+		e := Error{File: p.file, Msg: msg}
+		var line int
+		if _, scanErr := fmt.Sscanf(msg, "line %d:", &line); scanErr == nil {
+			e.Line = line
+			e.Msg = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+		}
+		p.errs = append(p.errs, e)
+	}
+}
+
+// err returns the collected problems sorted by file and position, or nil
+// when there are none.
+func (p *problems) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(p.errs, func(a, b Error) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return p.errs
+}
+
+// valueNode returns the value stored under key in a mapping node. When the
+// key is absent it returns the mapping itself, so that a problem with a
+// missing or defaulted field still points at the enclosing block.
+func valueNode(n *yaml.Node, key string) *yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return n
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return n
+}
+
+// itemNode returns the i-th element of a sequence node, or the node itself
+// when it is not a sequence or is too short.
+func itemNode(n *yaml.Node, i int) *yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+		return n
+	}
+	return n.Content[i]
+}
+
+// resolve follows document and alias nodes to the node holding the value.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			if len(n.Content) == 0 {
+				return nil
+			}
+			n = n.Content[0]
+		case yaml.AliasNode:
+			n = n.Alias
+		default:
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loader reads a config file and the files it includes, merging them into a
+// single document. Included files are merged in the order they are listed,
+// the matches of a glob in lexical order, and the including file is applied
+// last, so a file overrides everything it includes.
+type loader struct {
+	root *problems
+	// stack holds the files being loaded, outermost first, to detect cycles.
+	stack []string
+	// files holds every file loaded so far in merge order. A file included
+	// twice is merged only the first time.
+	files []string
+}
+
+// load parses data, read from file, and returns its top-level mapping
+// merged over the files it includes, without the include key. Problems are
+// recorded in p; the mapping is nil only when the document is empty. Only a
+// YAML syntax error in data itself is returned as an error.
+func (l *loader) load(file string, data []byte, p *problems) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshaling YAML: %w", err)
+	}
+	p.track(&doc)
+	p.checkKeys(&doc, reflect.TypeFor[Config](), "")
+
+	root := resolve(&doc)
+	if root == nil {
+		return nil, nil
+	}
+	var scratch Config
+	if err := doc.Decode(&scratch); err != nil {
+		p.addDecode(err)
+	}
+
+	var base *yaml.Node
+	includes := valueNode(root, "include")
+	for i, pattern := range scratch.Include {
+		item := itemNode(includes, i)
+		for _, path := range l.expand(file, pattern, item, p) {
+			if inc := l.include(path, item, p); inc != nil {
+				base = l.merge(base, inc)
+			}
+		}
+	}
+	return l.merge(base, l.withoutKey(root, "include")), nil
+}
+
+// expand resolves an include pattern relative to the directory of file. A
+// pattern without glob characters must name an existing file; a glob may
+// match nothing.
+func (l *loader) expand(file, pattern string, item *yaml.Node, p *problems) []string {
+	path, err := ExpandPath(pattern)
+	if err != nil {
+		p.addf(item, "include %q: %v", pattern, err)
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+
+	if !strings.ContainsAny(path, "*?[") {
+		return []string{path}
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		p.addf(item, "include %q: %v", pattern, err)
+		return nil
+	}
+	return matches
+}
+
+// include loads the file at path, recording problems that prevent it from
+// being read against item, the include entry naming it.
+func (l *loader) include(path string, item *yaml.Node, p *problems) *yaml.Node {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		p.addf(item, "include %s: %v", path, err)
+		return nil
+	}
+	if i := slices.Index(l.stack, abs); i >= 0 {
+		cycle := append(slices.Clone(l.stack[i:]), abs)
+		p.addf(item, "include cycle: %s", strings.Join(cycle, " -> "))
+		return nil
+	}
+	if slices.Contains(l.files, abs) {
+		return nil
+	}
+
+	data, err := os.ReadFile(abs) //nolint:gosec // path comes from the user's own config
+	if err != nil {
+		p.addf(item, "include %s: %v", path, err)
+		return nil
+	}
+
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	sub := &problems{file: abs, files: p.files}
+	doc, err := l.load(abs, data, sub)
+	if err != nil {
+		p.addf(item, "include %s: %v", path, err)
+	}
+	l.root.errs = append(l.root.errs, sub.errs...)
+	l.files = append(l.files, abs)
+	return doc
+}
+
+// merge returns a mapping holding base with over applied on top. Rules
+// with the same name, sources with the same path, categories and variables
+// are replaced in place; exclude and opaque lists are appended; any other
+// key is replaced. Neither input is modified.
+func (l *loader) merge(base, over *yaml.Node) *yaml.Node {
+	if over == nil || over.Kind != yaml.MappingNode {
+		return base
+	}
+	if base == nil {
+		return over
+	}
+
+	out := l.copyNode(over)
+	out.Content = slices.Clone(base.Content)
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, value := over.Content[i], over.Content[i+1]
+		j := keyIndex(out, key.Value)
+		if j < 0 {
+			out.Content = append(out.Content, key, value)
+			continue
+		}
+
+		prev := out.Content[j+1]
+		switch key.Value {
+		case "rules":
+			value = l.mergeSequence(prev, value, ruleName)
+		case "sources":
+			value = l.mergeSequence(prev, value, sourcePath)
+		case "exclude", "opaque":
+			value = l.mergeSequence(prev, value, nil)
+		case "categories", "vars":
+			value = l.merge(resolve(prev), resolve(value))
+		}
+		out.Content[j], out.Content[j+1] = key, value
+	}
+	return out
+}
+
+// mergeSequence appends the items of over to those of base. An item for
+// which name returns a non-empty name replaces the base item with the same
+// name instead. When either node is not a sequence, over replaces base.
+func (l *loader) mergeSequence(base, over *yaml.Node, name func(*yaml.Node) string) *yaml.Node {
+	b, o := resolve(base), resolve(over)
+	if b == nil || o == nil || b.Kind != yaml.SequenceNode || o.Kind != yaml.SequenceNode {
+		return over
+	}
+
+	out := l.copyNode(o)
+	out.Content = slices.Clone(b.Content)
+	for _, item := range o.Content {
+		if name != nil {
+			if n := name(item); n != "" {
+				if j := slices.IndexFunc(out.Content, func(prev *yaml.Node) bool { return name(prev) == n }); j >= 0 {
+					out.Content[j] = item
+					continue
+				}
+			}
+		}
+		out.Content = append(out.Content, item)
+	}
+	return out
+}
+
+// ruleName returns the name of a rule node, which defaults to its preset.
+func ruleName(n *yaml.Node) string {
+	n = resolve(n)
+	for _, key := range []string{"name", "preset"} {
+		if i := keyIndex(n, key); i >= 0 {
+			if v := resolve(n.Content[i+1]); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+				return v.Value
+			}
+		}
+	}
+	return ""
+}
+
+// sourcePath returns the path of a source node.
+func sourcePath(n *yaml.Node) string {
+	n = resolve(n)
+	if i := keyIndex(n, "path"); i >= 0 {
+		if v := resolve(n.Content[i+1]); v != nil && v.Kind == yaml.ScalarNode {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+// keyIndex returns the index of key in the content of mapping n, or -1.
+func keyIndex(n *yaml.Node, key string) int {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutKey returns mapping n without key, leaving n unmodified.
+func (l *loader) withoutKey(n *yaml.Node, key string) *yaml.Node {
+	i := keyIndex(n, key)
+	if i < 0 {
+		return n
+	}
+	out := l.copyNode(n)
+	out.Content = slices.Delete(slices.Clone(n.Content), i, i+2)
+	return out
+}
+
+// copyNode returns a shallow copy of n attributed to the same file.
+func (l *loader) copyNode(n *yaml.Node) *yaml.Node {
+	out := *n
+	if file, ok := l.root.files[n]; ok {
+		l.root.files[&out] = file
+	}
+	return &out
+}
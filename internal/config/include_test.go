@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfigs writes each file under dir and returns dir.
+func writeConfigs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_Include(t *testing.T) {
+	srcDir := t.TempDir()
+	dir := writeConfigs(t, map[string]string{
+		"team.yaml": fmt.Sprintf(`source: %s
+vars: {root: /team, docs: /team/docs}
+conflict: skip
+exclude: ["*.part"]
+rules:
+  - name: images
+    match: {extensions: [.jpg]}
+    destination: ${root}/images
+  - name: docs
+    match: {extensions: [.pdf]}
+    destination: /team/docs
+`, srcDir),
+		"conf.d/20-music.yaml": "rules:\n  - preset: audio\n    destination: /music\n",
+		"conf.d/10-video.yaml": "rules:\n  - preset: videos\n",
+		"conf.d/notes.txt":     "not yaml: [",
+		".forg.yaml": `include:
+  - team.yaml
+  - conf.d/*.yaml
+  - empty.d/*.yaml
+conflict: rename
+vars: {root: /mine}
+exclude: ["*.tmp"]
+rules:
+  - name: docs
+    match: {extensions: [.pdf, .docx]}
+    destination: /mine/docs
+  - name: logs
+    match: {extensions: [.log]}
+    destination: /mine/logs
+`,
+	})
+	main := filepath.Join(dir, ".forg.yaml")
+
+	cfg, err := Load(main)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Source != srcDir || cfg.Conflict != "rename" {
+		t.Errorf("source, conflict = %s, %s; want %s, rename", cfg.Source, cfg.Conflict, srcDir)
+	}
+	if want := []string{"*.part", "*.tmp"}; !slices.Equal(cfg.Exclude, want) {
+		t.Errorf("Exclude = %v, want %v", cfg.Exclude, want)
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		origin      string
+	}{
+		{"images", "/mine/images", "team.yaml"},
+		{"docs", "/mine/docs", ".forg.yaml"},
+		{"videos", "~/Videos", "conf.d/10-video.yaml"},
+		{"audio", "/music", "conf.d/20-music.yaml"},
+		{"logs", "/mine/logs", ".forg.yaml"},
+	}
+	if len(cfg.Rules) != len(tests) {
+		t.Fatalf("got %d rules, want %d: %+v", len(cfg.Rules), len(tests), cfg.Rules)
+	}
+	for i, tt := range tests {
+		r := cfg.Rules[i]
+		if r.Name != tt.name || r.Destination != tt.destination || r.Origin != filepath.Join(dir, tt.origin) {
+			t.Errorf("rule %d = %s -> %s from %s; want %s -> %s from %s", i, r.Name, r.Destination, r.Origin, tt.name, tt.destination, tt.origin)
+		}
+	}
+
+	wantFiles := []string{"team.yaml", "conf.d/10-video.yaml", "conf.d/20-music.yaml", ".forg.yaml"}
+	for i := range wantFiles {
+		wantFiles[i] = filepath.Join(dir, wantFiles[i])
+	}
+	if !slices.Equal(cfg.Files, wantFiles) {
+		t.Errorf("Files = %v, want %v", cfg.Files, wantFiles)
+	}
+}
+
+func TestLoad_IncludeErrors(t *testing.T) {
+	srcDir := t.TempDir()
+	rules := "rules:\n  - name: r\n    match: {extensions: [.jpg]}\n    destination: /out\n"
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantError string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				".forg.yaml": "include: [a.yaml]\nsource: " + srcDir + "\n" + rules,
+				"a.yaml":     "include: [b.yaml]\n",
+				"b.yaml":     "include: [a.yaml]\n",
+			},
+			wantError: "b.yaml:1:11: include cycle: DIR/a.yaml -> DIR/b.yaml -> DIR/a.yaml",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				".forg.yaml": "include: [.forg.yaml]\nsource: " + srcDir + "\n" + rules,
+			},
+			wantError: ".forg.yaml:1:11: include cycle: DIR/.forg.yaml -> DIR/.forg.yaml",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				".forg.yaml": "source: " + srcDir + "\ninclude:\n  - missing.yaml\n" + rules,
+			},
+			wantError: ".forg.yaml:3:5: include DIR/missing.yaml:",
+		},
+		{
+			name: "problem in included file",
+			files: map[string]string{
+				".forg.yaml": "include: [team.yaml]\nsource: " + srcDir + "\n" + rules,
+				"team.yaml":  "conflict: merge\nsymlnks: skip\n",
+			},
+			wantError: "DIR/team.yaml:1:11: invalid conflict strategy \"merge\"",
+		},
+		{
+			name: "unknown key in included file",
+			files: map[string]string{
+				".forg.yaml": "include: [team.yaml]\nsource: " + srcDir + "\n" + rules,
+				"team.yaml":  "conflict: skip\nsymlnks: skip\n",
+			},
+			wantError: "DIR/team.yaml:2:1: unknown key \"symlnks\" (did you mean \"symlinks\"?)",
+		},
+		{
+			name: "syntax error in included file",
+			files: map[string]string{
+				".forg.yaml": "include: [team.yaml]\nsource: " + srcDir + "\n" + rules,
+				"team.yaml":  "rules: [\n",
+			},
+			wantError: ".forg.yaml:1:11: include DIR/team.yaml: unmarshaling YAML:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigs(t, tt.files)
+			_, err := Load(filepath.Join(dir, ".forg.yaml"))
+			if err == nil {
+				t.Fatal("Load() expected an error, got nil")
+			}
+			want := strings.ReplaceAll(tt.wantError, "DIR", dir)
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Load() error = %q, want it to contain %q", err.Error(), want)
+			}
+		})
+	}
+}
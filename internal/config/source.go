@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/devaloi/forg/internal/category"
+	"github.com/devaloi/forg/internal/ignore"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceConfig is one directory to organize, with its own scan options and
+// optionally its own rules.
+type SourceConfig struct {
+	Path          string `yaml:"path"`
+	Recursive     bool   `yaml:"recursive,omitempty"`
+	IncludeHidden bool   `yaml:"include_hidden,omitempty"`
+	// Exclude is applied in addition to the config-wide exclude patterns.
+	Exclude []string `yaml:"exclude,omitempty"`
+	// Rules apply only to this source and are evaluated before the rules
+	// named in Use.
+	Rules []RuleConfig `yaml:"rules,omitempty"`
+	// Use names top-level rules that apply to this source, in evaluation
+	// order. When both Rules and Use are empty, every top-level rule
+	// applies.
+	Use []string `yaml:"use,omitempty"`
+}
+
+// AllSources returns the directories to organize: the sources list, or a
+// single source for the source key with no options of its own.
+func (c *Config) AllSources() []SourceConfig {
+	if len(c.Sources) > 0 {
+		return c.Sources
+	}
+	return []SourceConfig{{Path: c.Source}}
+}
+
+// RulesFor returns the rules that apply to files from src, in evaluation
+// order.
+func (c *Config) RulesFor(src SourceConfig) []RuleConfig {
+	if len(src.Rules) == 0 && len(src.Use) == 0 {
+		return c.Rules
+	}
+	rules := slices.Clone(src.Rules)
+	for _, name := range src.Use {
+		if i := slices.IndexFunc(c.Rules, func(r RuleConfig) bool { return r.Name == name }); i >= 0 {
+			rules = append(rules, c.Rules[i])
+		}
+	}
+	return rules
+}
+
+// AllRules returns the top-level rules followed by the rules of each
+// source.
+func (c *Config) AllRules() []RuleConfig {
+	rules := slices.Clone(c.Rules)
+	for _, src := range c.Sources {
+		rules = append(rules, src.Rules...)
+	}
+	return rules
+}
+
+// checkSource validates a source directory, recording problems against
+// node. It returns the expanded path, or "" when the source is unusable.
+func checkSource(path string, node *yaml.Node, p *problems) string {
+	if path == "" {
+		p.addf(node, "source directory is required")
+		return ""
+	}
+	expanded, err := ExpandPath(path)
+	if err != nil {
+		p.addf(node, "expanding source path: %v", err)
+		return ""
+	}
+	info, err := os.Stat(expanded)
+	switch {
+	case err != nil:
+		p.addf(node, "source directory %s: %v", expanded, err)
+		return ""
+	case !info.IsDir():
+		p.addf(node, "source path %s is not a directory", expanded)
+		return ""
+	}
+	return expanded
+}
+
+// validateSources checks the sources list, whose node is n, and the rules
+// each source defines or uses.
+func validateSources(cfg *Config, n *yaml.Node, catalog category.Catalog, p *problems) {
+	names := make([]string, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		names = append(names, r.Name)
+	}
+	seen := make(map[string]bool)
+	// dirs holds the absolute directory and label of each usable source.
+	type sourceDir struct{ abs, label string }
+	var dirs []sourceDir
+
+	for i := range cfg.Sources {
+		src := &cfg.Sources[i]
+		node := itemNode(n, i)
+		label := fmt.Sprintf("source %d", i)
+		if src.Path != "" {
+			label = fmt.Sprintf("source %q", src.Path)
+		}
+
+		expanded := checkSource(src.Path, valueNode(node, "path"), p)
+		if expanded != "" {
+			if abs, err := filepath.Abs(expanded); err == nil {
+				if seen[abs] {
+					p.addf(valueNode(node, "path"), "%s: directory is listed more than once", label)
+				} else {
+					dirs = append(dirs, sourceDir{abs, label})
+				}
+				seen[abs] = true
+			}
+		}
+
+		for j, pattern := range src.Exclude {
+			if _, err := ignore.Compile(pattern); err != nil {
+				p.addf(itemNode(valueNode(node, "exclude"), j), "%s: exclude: %v", label, err)
+			}
+		}
+
+		for j, name := range src.Use {
+			if !slices.Contains(names, name) {
+				msg := fmt.Sprintf("%s: unknown rule %q", label, name)
+				if s := suggest(name, names); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				p.addf(itemNode(valueNode(node, "use"), j), "%s", msg)
+			}
+		}
+
+		rules := valueNode(node, "rules")
+		for j := range src.Rules {
+			resolveCategory(j, &src.Rules[j], catalog, itemNode(rules, j), p)
+		}
+		for j, rule := range src.Rules {
+			validateRule(j, rule, cfg.Symlinks, itemNode(rules, j), p)
+		}
+
+		if len(src.Rules) == 0 && len(src.Use) == 0 && len(cfg.Rules) == 0 {
+			p.addf(node, "%s: no rules apply; give it rules or define top-level rules", label)
+		}
+
+		if expanded != "" {
+			for _, rule := range cfg.RulesFor(*src) {
+				for _, w := range destinationWarnings(expanded, rule) {
+					if !slices.Contains(cfg.Warnings, w) {
+						cfg.Warnings = append(cfg.Warnings, w)
+					}
+				}
+			}
+		}
+	}
+
+	// A scan skips any other source beneath its directory, so a nested
+	// source's files are organized by its own rules only.
+	for _, inner := range dirs {
+		for _, outer := range dirs {
+			if IsWithin(inner.abs, outer.abs) {
+				cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s is inside %s, which skips it; its files are organized by its own rules only", inner.label, outer.label))
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ruleNames returns the name of each rule.
+func ruleNames(rules []RuleConfig) []string {
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestParse_Sources(t *testing.T) {
+	downloads, desktop, scans := t.TempDir(), t.TempDir(), t.TempDir()
+
+	cfg, err := Parse([]byte(fmt.Sprintf(`vars: {scans: %s}
+exclude: ["*.part"]
+rules:
+  - name: images
+    match: {extensions: [.jpg]}
+    destination: /out/images
+  - name: docs
+    match: {extensions: [.pdf]}
+    destination: /out/docs
+sources:
+  - path: %s
+    recursive: true
+  - path: %s
+    include_hidden: true
+    exclude: ["*.tmp"]
+    use: [docs]
+  - path: ${scans}
+    rules:
+      - name: scans
+        match: {extensions: [.tiff]}
+        destination: /out/scans
+    use: [images]
+`, scans, downloads, desktop)))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(cfg.Sources) != 3 || cfg.Sources[2].Path != scans {
+		t.Fatalf("Sources = %+v, want 3 sources ending in %s", cfg.Sources, scans)
+	}
+	if src := cfg.Sources[0]; !src.Recursive || src.IncludeHidden {
+		t.Errorf("source 0 = %+v, want recursive only", src)
+	}
+	if src := cfg.Sources[1]; !src.IncludeHidden || !slices.Equal(src.Exclude, []string{"*.tmp"}) {
+		t.Errorf("source 1 = %+v, want hidden files and *.tmp excluded", src)
+	}
+
+	tests := []struct {
+		source int
+		want   []string
+	}{
+		{0, []string{"images", "docs"}},
+		{1, []string{"docs"}},
+		{2, []string{"scans", "images"}},
+	}
+	for _, tt := range tests {
+		if got := ruleNames(cfg.RulesFor(cfg.Sources[tt.source])); !slices.Equal(got, tt.want) {
+			t.Errorf("RulesFor(source %d) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+
+	if got, want := ruleNames(cfg.AllRules()), []string{"images", "docs", "scans"}; !slices.Equal(got, want) {
+		t.Errorf("AllRules() = %v, want %v", got, want)
+	}
+}
+
+func TestAllSources_Single(t *testing.T) {
+	cfg := &Config{Source: "~/Downloads"}
+	got := cfg.AllSources()
+	if len(got) != 1 || got[0].Path != "~/Downloads" || got[0].Recursive {
+		t.Errorf("AllSources() = %+v, want a single source for ~/Downloads", got)
+	}
+}
+
+func TestParse_NestedSources(t *testing.T) {
+	outer := t.TempDir()
+	inner := filepath.Join(outer, "inner")
+	if err := os.Mkdir(inner, 0o750); err != nil {
+		t.Fatalf("creating inner source: %v", err)
+	}
+
+	cfg, err := Parse([]byte(fmt.Sprintf(`sources:
+  - path: %s
+    recursive: true
+  - path: %s
+rules:
+  - name: images
+    match: {extensions: [.jpg]}
+    destination: /out
+`, outer, inner)))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	want := fmt.Sprintf("source %q is inside source %q, which skips it", inner, outer)
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], want) {
+		t.Errorf("Warnings = %q, want one containing %q", cfg.Warnings, want)
+	}
+}
+
+func TestParse_SourceErrors(t *testing.T) {
+	srcDir := t.TempDir()
+	rule := "rules:\n  - name: images\n    match: {extensions: [.jpg]}\n    destination: /out\n"
+
+	tests := []struct {
+		name      string
+		yaml      string
+		wantError string
+	}{
+		{
+			name:      "source and sources",
+			yaml:      fmt.Sprintf("source: %s\nsources:\n  - path: %s\n", srcDir, srcDir) + rule,
+			wantError: "line 1, column 9: source and sources cannot both be set",
+		},
+		{
+			name:      "missing path",
+			yaml:      "sources:\n  - recursive: true\n" + rule,
+			wantError: "line 2, column 5: source directory is required",
+		},
+		{
+			name:      "missing directory",
+			yaml:      "sources:\n  - path: /nonexistent/forg/source\n" + rule,
+			wantError: "line 2, column 11: source directory /nonexistent/forg/source:",
+		},
+		{
+			name:      "listed twice",
+			yaml:      fmt.Sprintf("sources:\n  - path: %s\n  - path: %s/\n", srcDir, srcDir) + rule,
+			wantError: fmt.Sprintf("line 3, column 11: source %q: directory is listed more than once", srcDir+"/"),
+		},
+		{
+			name:      "unknown rule",
+			yaml:      fmt.Sprintf("sources:\n  - path: %s\n    use: [imagse]\n", srcDir) + rule,
+			wantError: `line 3, column 11: source "` + srcDir + `": unknown rule "imagse" (did you mean "images"?)`,
+		},
+		{
+			name:      "invalid exclude",
+			yaml:      fmt.Sprintf("sources:\n  - path: %s\n    exclude: [\"[\"]\n", srcDir) + rule,
+			wantError: "line 3, column 15: source",
+		},
+		{
+			name:      "invalid inline rule",
+			yaml:      fmt.Sprintf("sources:\n  - path: %s\n    rules:\n      - name: bad\n        destination: /out\n", srcDir),
+			wantError: `line 4, column 9: rule "bad"`,
+		},
+		{
+			name:      "no rules apply",
+			yaml:      fmt.Sprintf("sources:\n  - path: %s\n", srcDir),
+			wantError: "line 2, column 5: source " + fmt.Sprintf("%q", srcDir) + ": no rules apply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("Parse() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestLoad_IncludeSources(t *testing.T) {
+	downloads, desktop := t.TempDir(), t.TempDir()
+	dir := writeConfigs(t, map[string]string{
+		"team.yaml": fmt.Sprintf(`sources:
+  - path: %s
+  - path: %s
+    recursive: true
+rules:
+  - name: images
+    match: {extensions: [.jpg]}
+    destination: /out
+`, downloads, desktop),
+		".forg.yaml": fmt.Sprintf(`include: [team.yaml]
+sources:
+  - path: %s
+    include_hidden: true
+`, desktop),
+	})
+
+	cfg, err := Load(filepath.Join(dir, ".forg.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if len(cfg.Sources) != 2 {
+		t.Fatalf("Sources = %+v, want 2", cfg.Sources)
+	}
+	if src := cfg.Sources[1]; src.Path != desktop || src.Recursive || !src.IncludeHidden {
+		t.Errorf("source 1 = %+v, want %s replaced by the including file", src, desktop)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkKeys reports every mapping key in n that has no matching field in t,
+// descending into nested structs and slices. where names the enclosing block
+// for the message and is empty at the top level.
+func (p *problems) checkKeys(n *yaml.Node, t reflect.Type, where string) {
+	n = resolve(n)
+	if n == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return // the decoder reports the type mismatch
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Tag == "!!merge" {
+				p.checkMerge(value, t, where)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				p.unknownKey(key, where, fields)
+				continue
+			}
+			p.checkKeys(value, field, key.Value)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range n.Content {
+			p.checkKeys(item, t.Elem(), where)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			p.checkKeys(n.Content[i+1], t.Elem(), where)
+		}
+	}
+}
+
+// checkMerge checks the mappings pulled in by a "<<" merge key.
+func (p *problems) checkMerge(n *yaml.Node, t reflect.Type, where string) {
+	n = resolve(n)
+	if n != nil && n.Kind == yaml.SequenceNode {
+		for _, item := range n.Content {
+			p.checkKeys(item, t, where)
+		}
+		return
+	}
+	p.checkKeys(n, t, where)
+}
+
+// unknownKey records a key that does not belong in its block, suggesting the
+// closest known key when one is near enough to be a likely typo.
+func (p *problems) unknownKey(key *yaml.Node, where string, fields map[string]reflect.Type) {
+	msg := fmt.Sprintf("unknown key %q", key.Value)
+	if where != "" {
+		msg += " in " + where
+	}
+	if s := suggest(key.Value, slices.Collect(maps.Keys(fields))); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	p.addf(key, "%s", msg)
+}
+
+// yamlFields maps each yaml key a struct accepts to the field's type.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// suggest returns the candidate closest to name, or "" when none is close.
+// Hyphens are treated as underscores, so "min-size" suggests "min_size".
+func suggest(name string, candidates []string) string {
+	normalized := strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	best, bestDist := "", len(normalized)/3+1
+	for _, known := range candidates {
+		d := editDistance(normalized, known)
+		if d < bestDist || d == bestDist && best != "" && known < best {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varNamePattern matches the names allowed in the vars block.
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// errVarReported marks a reference to a variable whose own value has an
+// error that was already reported.
+var errVarReported = errors.New("variable has errors")
+
+// expander resolves ${...} references in config values. ${NAME} is the
+// variable NAME from the vars block, ${env:NAME} the environment variable
+// NAME, and either form may end in :-default to supply a value when the
+// variable is unset or empty. $${ is a literal ${.
+type expander struct {
+	vars     map[string]string
+	resolved map[string]string
+	failed   map[string]bool
+	// resolving holds the variables being expanded, to detect cycles.
+	resolving []string
+}
+
+// expand returns s with every reference replaced by its value.
+func (e *expander) expand(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1])
+			b.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+		b.WriteString(s[:i])
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference %q", s[i:])
+		}
+		value, err := e.reference(s[i+2 : i+end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		s = s[i+end+1:]
+	}
+}
+
+// reference returns the value of ref, the text between ${ and }.
+func (e *expander) reference(ref string) (string, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+
+	var value string
+	var err error
+	if env, ok := strings.CutPrefix(name, "env:"); ok {
+		if !varNamePattern.MatchString(env) {
+			return "", fmt.Errorf("invalid environment variable name %q", env)
+		}
+		var set bool
+		value, set = os.LookupEnv(env)
+		if !set {
+			err = fmt.Errorf("environment variable %s is not set", env)
+		}
+	} else {
+		if !varNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+		value, err = e.lookup(name)
+	}
+
+	if hasDefault && value == "" && !errors.Is(err, errVarReported) {
+		return def, nil
+	}
+	return value, err
+}
+
+// lookup returns the expanded value of the variable name.
+func (e *expander) lookup(name string) (string, error) {
+	if v, ok := e.resolved[name]; ok {
+		return v, nil
+	}
+	if e.failed[name] {
+		return "", errVarReported
+	}
+	raw, ok := e.vars[name]
+	if !ok {
+		return "", fmt.Errorf("undefined variable %s", name)
+	}
+	if i := slices.Index(e.resolving, name); i >= 0 {
+		cycle := append(slices.Clone(e.resolving[i:]), name)
+		return "", fmt.Errorf("variable cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	e.resolving = append(e.resolving, name)
+	v, err := e.expand(raw)
+	e.resolving = e.resolving[:len(e.resolving)-1]
+	if err != nil {
+		return "", err
+	}
+	e.resolved[name] = v
+	return v, nil
+}
+
+// expandVars replaces references in the source paths, rule destinations and
+// category folders of cfg, recording unknown variables and other problems
+// against the nodes in doc they came from. Each variable is checked even
+// when nothing refers to it.
+func expandVars(cfg *Config, doc *yaml.Node, p *problems) {
+	e := &expander{vars: cfg.Vars, resolved: make(map[string]string), failed: make(map[string]bool)}
+
+	vars := valueNode(doc, "vars")
+	for _, name := range slices.Sorted(maps.Keys(cfg.Vars)) {
+		if !varNamePattern.MatchString(name) {
+			p.addf(valueNode(vars, name), "vars: invalid variable name %q", name)
+			e.failed[name] = true
+			continue
+		}
+		if _, err := e.lookup(name); err != nil {
+			if !errors.Is(err, errVarReported) {
+				p.addf(valueNode(vars, name), "vars: %s: %v", name, err)
+			}
+			e.failed[name] = true
+		}
+	}
+
+	field := func(value *string, node *yaml.Node, label string) {
+		expanded, err := e.expand(*value)
+		switch {
+		case errors.Is(err, errVarReported):
+		case err != nil:
+			p.addf(node, "%s: %v", label, err)
+		default:
+			*value = expanded
+		}
+	}
+
+	field(&cfg.Source, valueNode(doc, "source"), "source")
+
+	sources := valueNode(doc, "sources")
+	for i := range cfg.Sources {
+		field(&cfg.Sources[i].Path, valueNode(itemNode(sources, i), "path"), fmt.Sprintf("source %d: path", i))
+	}
+
+	categories := valueNode(doc, "categories")
+	for name, def := range cfg.Categories {
+		field(&def.Folder, valueNode(valueNode(categories, name), "folder"), fmt.Sprintf("category %q: folder", name))
+		cfg.Categories[name] = def
+	}
+
+	expandDestinations(cfg.Rules, valueNode(doc, "rules"), field)
+	for i := range cfg.Sources {
+		expandDestinations(cfg.Sources[i].Rules, valueNode(itemNode(sources, i), "rules"), field)
+	}
+}
+
+// expandDestinations applies field to the destination of each rule, whose
+// node in the rules list n is passed along.
+func expandDestinations(rules []RuleConfig, n *yaml.Node, field func(*string, *yaml.Node, string)) {
+	for i := range rules {
+		rule := &rules[i]
+		field(&rule.Destination, valueNode(itemNode(n, i), "destination"), ruleLabel(i, *rule)+": destination")
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpander(t *testing.T) {
+	t.Setenv("FORG_TEST_NAS", "/mnt/nas")
+	t.Setenv("FORG_TEST_EMPTY", "")
+
+	e := &expander{
+		vars: map[string]string{
+			"base":   "/data",
+			"photos": "${base}/Photos",
+			"nas":    "${env:FORG_TEST_NAS}",
+			"empty":  "",
+			"loop_a": "${loop_b}",
+			"loop_b": "${loop_a}",
+		},
+		resolved: make(map[string]string),
+		failed:   make(map[string]bool),
+	}
+
+	tests := []struct {
+		in        string
+		want      string
+		wantError string
+	}{
+		{in: "~/Pictures", want: "~/Pictures"},
+		{in: "${base}/Music", want: "/data/Music"},
+		{in: "${photos}/2024", want: "/data/Photos/2024"},
+		{in: "${nas}/Photos", want: "/mnt/nas/Photos"},
+		{in: "${env:FORG_TEST_NAS}/x", want: "/mnt/nas/x"},
+		{in: "${missing:-/fallback}/x", want: "/fallback/x"},
+		{in: "${empty:-/fallback}", want: "/fallback"},
+		{in: "${env:FORG_TEST_EMPTY:-/tmp}", want: "/tmp"},
+		{in: "${env:FORG_TEST_UNSET_VAR:-/tmp}", want: "/tmp"},
+		{in: "${base:-/unused}", want: "/data"},
+		{in: "$${base} and $HOME", want: "${base} and $HOME"},
+		{in: "${missing}/x", wantError: "undefined variable missing"},
+		{in: "${env:FORG_TEST_UNSET_VAR}", wantError: "environment variable FORG_TEST_UNSET_VAR is not set"},
+		{in: "${not valid}", wantError: `invalid variable name "not valid"`},
+		{in: "${base", wantError: `unterminated reference "${base"`},
+		{in: "${loop_a}", wantError: "variable cycle: loop_a -> loop_b -> loop_a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := e.expand(tt.in)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("expand(%q) error = %v, want %q", tt.in, err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expand(%q) error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Vars(t *testing.T) {
+	srcDir := t.TempDir()
+	t.Setenv("FORG_TEST_SRC", srcDir)
+
+	cfg, err := Parse([]byte(`vars:
+  nas: /mnt/nas
+  photos: ${nas}/Photos
+source: ${env:FORG_TEST_SRC}
+categories:
+  scans:
+    extensions: [.tiff]
+    folder: ${nas}/Scans
+rules:
+  - name: images
+    match: {extensions: [.jpg]}
+    destination: ${photos}/Sorted
+  - preset: scans
+  - name: music
+    match: {extensions: [.mp3]}
+    destination: ${music:-~/Music}
+`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if cfg.Source != srcDir {
+		t.Errorf("Source = %q, want %q", cfg.Source, srcDir)
+	}
+	want := []string{"/mnt/nas/Photos/Sorted", "/mnt/nas/Scans", "~/Music"}
+	for i, w := range want {
+		if got := cfg.Rules[i].Destination; got != w {
+			t.Errorf("rule %s destination = %q, want %q", cfg.Rules[i].Name, got, w)
+		}
+	}
+}
+
+func TestParse_VarErrors(t *testing.T) {
+	srcDir := t.TempDir()
+	rule := "rules:\n  - name: r\n    match: {extensions: [.jpg]}\n    destination: /out\n"
+
+	tests := []struct {
+		name      string
+		yaml      string
+		wantError string
+	}{
+		{
+			name:      "undefined in destination",
+			yaml:      fmt.Sprintf("source: %s\nrules:\n  - name: r\n    match: {extensions: [.jpg]}\n    destination: ${nas}/Photos\n", srcDir),
+			wantError: `line 5, column 18: rule "r": destination: undefined variable nas`,
+		},
+		{
+			name:      "undefined in source",
+			yaml:      "source: ${env:FORG_TEST_UNSET_VAR}/in\n" + rule,
+			wantError: `line 1, column 9: source: environment variable FORG_TEST_UNSET_VAR is not set`,
+		},
+		{
+			name:      "undefined in var",
+			yaml:      fmt.Sprintf("vars:\n  photos: ${nas}/Photos\nsource: %s\n", srcDir) + rule,
+			wantError: `line 2, column 11: vars: photos: undefined variable nas`,
+		},
+		{
+			name:      "invalid name",
+			yaml:      fmt.Sprintf("vars:\n  my-dir: /x\nsource: %s\n", srcDir) + rule,
+			wantError: `line 2, column 11: vars: invalid variable name "my-dir"`,
+		},
+		{
+			name:      "cycle",
+			yaml:      fmt.Sprintf("vars:\n  a: ${b}\n  b: ${a}\nsource: %s\n", srcDir) + rule,
+			wantError: `vars: a: variable cycle: a -> b -> a`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("Parse() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
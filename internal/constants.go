@@ -14,12 +14,41 @@ const (
 	// DefaultConfigFile is the default configuration file name.
 	DefaultConfigFile = ".forg.yaml"
 
+	// DefaultTestFile is the default file of rule test cases for forg test.
+	DefaultTestFile = ".forg.test.yaml"
+
+	// Unmatched is the expected rule of a test case that no rule should
+	// match.
+	Unmatched = "unmatched"
+
+	// OriginBuiltin marks a category that ships with forg unchanged.
+	OriginBuiltin = "built-in"
+
+	// OriginConfig marks a category defined only in the config.
+	OriginConfig = "config"
+
+	// OriginExtended marks a built-in category whose extensions the config
+	// adds to.
+	OriginExtended = "extended"
+
+	// OriginOverridden marks a built-in category whose extensions the config
+	// replaces.
+	OriginOverridden = "overridden"
+
+	// IgnoreFile is the per-directory file listing gitignore-style patterns
+	// the scanner should skip.
+	IgnoreFile = ".forgignore"
+
 	// UndoLogDir is the directory name (under $HOME) that stores undo state.
 	UndoLogDir = ".forg"
 
 	// UndoLogFile is the file name used for the JSON undo log.
 	UndoLogFile = "undo.json"
 
+	// CacheDir is the directory name (under UndoLogDir) that stores scan
+	// caches, one file per source directory.
+	CacheDir = "cache"
+
 	// TimeFormat is the timestamp layout used when displaying undo metadata.
 	TimeFormat = "2006-01-02 15:04:05"
 
@@ -31,6 +60,92 @@ const (
 
 	// ConflictOverwrite replaces the existing destination file.
 	ConflictOverwrite = "overwrite"
+
+	// SymlinkSkip ignores symbolic links entirely.
+	SymlinkSkip = "skip"
+
+	// SymlinkMoveLink treats a symbolic link as a file and moves the link itself.
+	SymlinkMoveLink = "move-link"
+
+	// SymlinkFollow evaluates symbolic links by their targets and descends
+	// into linked directories.
+	SymlinkFollow = "follow"
+
+	// ProgressAuto shows a progress bar when stderr is a terminal and
+	// periodic log lines when it is redirected.
+	ProgressAuto = "auto"
+
+	// ProgressAlways draws the progress bar even when stderr is not a
+	// terminal.
+	ProgressAlways = "always"
+
+	// ProgressNever disables progress output.
+	ProgressNever = "never"
+
+	// ActionMove moves a file under its own name.
+	ActionMove = "move"
+
+	// ActionRename moves a file under a suffixed name because the
+	// destination already existed.
+	ActionRename = "rename"
+
+	// ActionOverwrite moves a file over an existing destination file.
+	ActionOverwrite = "overwrite"
+
+	// ActionSkip leaves a file in place because the destination existed.
+	ActionSkip = "skip"
+
+	// StatusPlanned marks an operation listed by a dry run.
+	StatusPlanned = "planned"
+
+	// StatusMoved marks a file moved without a conflict.
+	StatusMoved = "moved"
+
+	// StatusConflict marks a file moved after resolving a conflict by
+	// renaming or overwriting.
+	StatusConflict = "conflict"
+
+	// StatusSkipped marks a file left in place because of a conflict.
+	StatusSkipped = "skipped"
+
+	// StatusError marks a file that could not be moved.
+	StatusError = "error"
+
+	// OutputTable prints a human-readable table.
+	OutputTable = "table"
+
+	// OutputJSON prints the report as a single JSON document.
+	OutputJSON = "json"
+
+	// OutputJSONL prints one JSON object per operation.
+	OutputJSONL = "jsonl"
+
+	// OutputCSV prints one CSV row per operation.
+	OutputCSV = "csv"
+
+	// FileTypeRegular is the type of regular files.
+	FileTypeRegular = "file"
+
+	// FileTypeDir is the type of directories.
+	FileTypeDir = "dir"
+
+	// FileTypeSymlink is the type of symbolic links.
+	FileTypeSymlink = "symlink"
+
+	// FileTypeFIFO is the type of named pipes.
+	FileTypeFIFO = "fifo"
+
+	// FileTypeSocket is the type of Unix domain sockets.
+	FileTypeSocket = "socket"
+
+	// FileTypeDevice is the type of block device nodes.
+	FileTypeDevice = "device"
+
+	// FileTypeCharDevice is the type of character device nodes.
+	FileTypeCharDevice = "char-device"
+
+	// FileTypeIrregular is the type of entries the OS reports as irregular.
+	FileTypeIrregular = "irregular"
 )
 
 // ValidConflictStrategy reports whether s is a recognised conflict strategy.
@@ -42,3 +157,55 @@ func ValidConflictStrategy(s string) bool {
 		return false
 	}
 }
+
+// ValidSymlinkPolicy reports whether s is a recognised symlink policy.
+func ValidSymlinkPolicy(s string) bool {
+	switch s {
+	case SymlinkSkip, SymlinkMoveLink, SymlinkFollow:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidProgressMode reports whether s is a recognised progress mode.
+func ValidProgressMode(s string) bool {
+	switch s {
+	case ProgressAuto, ProgressAlways, ProgressNever:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidOutputFormat reports whether s is a recognised output format.
+func ValidOutputFormat(s string) bool {
+	switch s {
+	case OutputTable, OutputJSON, OutputJSONL, OutputCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidFileType reports whether s is a recognised file type name.
+func ValidFileType(s string) bool {
+	switch s {
+	case FileTypeRegular, FileTypeDir, FileTypeSymlink, FileTypeFIFO,
+		FileTypeSocket, FileTypeDevice, FileTypeCharDevice, FileTypeIrregular:
+		return true
+	default:
+		return false
+	}
+}
+
+// SpecialFileType reports whether s names a type that the scanner skips
+// unless a rule asks for it explicitly.
+func SpecialFileType(s string) bool {
+	switch s {
+	case FileTypeFIFO, FileTypeSocket, FileTypeDevice, FileTypeCharDevice, FileTypeIrregular:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,261 @@
+// Package fixture loads rule test cases and checks them against a rules
+// engine. Each case describes a virtual file, so configs can be tested
+// without creating or moving anything on disk.
+package fixture
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/rules"
+	"github.com/devaloi/forg/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a file of test cases.
+type Suite struct {
+	Cases []Case `yaml:"cases"`
+}
+
+// Case is a virtual file and the rule expected to match it.
+type Case struct {
+	// File is the path relative to the source directory; its depth counts
+	// like a scanned file's.
+	File string `yaml:"file"`
+	// Size is a size string such as "5MB". When it is empty the size is
+	// the length of Content.
+	Size    string `yaml:"size,omitempty"`
+	Content string `yaml:"content,omitempty"`
+	// MTime is the modification time as a date or RFC 3339 timestamp. Age
+	// is the alternative relative form, such as "45d". The default is the
+	// time the suite runs.
+	MTime string `yaml:"mtime,omitempty"`
+	Age   string `yaml:"age,omitempty"`
+	// Type is the entry type; the default is a regular file.
+	Type string `yaml:"type,omitempty"`
+	// Source is the path of the config source the file is placed in, as
+	// listed under sources; the default is the first source. The case is
+	// checked against the rules that apply to that source.
+	Source string `yaml:"source,omitempty"`
+	// Expect names the rule that should win, or internal.Unmatched.
+	Expect string `yaml:"expect"`
+}
+
+// Result is the outcome of one case.
+type Result struct {
+	Case Case
+	// Got names the rule that matched, or internal.Unmatched.
+	Got    string
+	Passed bool
+}
+
+// mtimeLayouts are the accepted forms of Case.MTime.
+var mtimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// Load reads a suite of test cases from path. Unknown keys are rejected.
+func Load(path string) (*Suite, error) {
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("expanding test file path: %w", err)
+	}
+
+	data, err := os.ReadFile(expanded) //nolint:gosec // path is controlled by caller
+	if err != nil {
+		return nil, fmt.Errorf("reading test file %s: %w", expanded, err)
+	}
+
+	suite, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing test file %s: %w", expanded, err)
+	}
+	return suite, nil
+}
+
+// Parse decodes a suite of test cases and checks that every case is
+// well-formed. All problems are reported together.
+func Parse(data []byte) (*Suite, error) {
+	var suite Suite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("unmarshaling YAML: %w", err)
+	}
+
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("at least one case is required")
+	}
+
+	var errs []error
+	for i, c := range suite.Cases {
+		if _, err := c.FileInfo("", time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("case %d (%s): %w", i+1, c.File, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// FileInfo returns the virtual file c describes, as a scan of source would
+// report it. Age is measured back from now.
+func (c Case) FileInfo(source string, now time.Time) (scanner.FileInfo, error) {
+	if c.File == "" {
+		return scanner.FileInfo{}, fmt.Errorf("file is required")
+	}
+	if c.Expect == "" {
+		return scanner.FileInfo{}, fmt.Errorf("expect is required: a rule name or %q", internal.Unmatched)
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(c.File))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return scanner.FileInfo{}, fmt.Errorf("file must be a path relative to the source directory")
+	}
+
+	name := filepath.Base(rel)
+	fi := scanner.FileInfo{
+		Path:      filepath.Join(source, rel),
+		Name:      name,
+		Extension: strings.ToLower(filepath.Ext(name)),
+		Size:      int64(len(c.Content)),
+		ModTime:   now,
+		Depth:     len(strings.Split(rel, string(filepath.Separator))),
+		Type:      internal.FileTypeRegular,
+	}
+
+	if c.Size != "" {
+		if c.Content != "" {
+			return scanner.FileInfo{}, fmt.Errorf("size and content are mutually exclusive")
+		}
+		size, err := config.ParseSize(c.Size)
+		if err != nil {
+			return scanner.FileInfo{}, fmt.Errorf("invalid size: %w", err)
+		}
+		fi.Size = size
+	}
+
+	switch {
+	case c.MTime != "" && c.Age != "":
+		return scanner.FileInfo{}, fmt.Errorf("mtime and age are mutually exclusive")
+	case c.MTime != "":
+		t, err := parseMTime(c.MTime)
+		if err != nil {
+			return scanner.FileInfo{}, err
+		}
+		fi.ModTime = t
+	case c.Age != "":
+		secs, err := config.ParseDuration(c.Age)
+		if err != nil {
+			return scanner.FileInfo{}, fmt.Errorf("invalid age: %w", err)
+		}
+		fi.ModTime = now.Add(-time.Duration(secs) * time.Second)
+	}
+
+	if c.Type != "" {
+		if !internal.ValidFileType(c.Type) {
+			return scanner.FileInfo{}, fmt.Errorf("invalid type %q", c.Type)
+		}
+		fi.Type = c.Type
+		fi.IsDir = c.Type == internal.FileTypeDir
+		fi.IsSymlink = c.Type == internal.FileTypeSymlink
+	}
+
+	return fi, nil
+}
+
+// parseMTime parses s in any of mtimeLayouts, reading zone-less forms as
+// local time.
+func parseMTime(s string) (time.Time, error) {
+	for _, layout := range mtimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid mtime %q: expected a date such as 2024-01-31 or an RFC 3339 timestamp", s)
+}
+
+// Run evaluates every case in suite against the rules of cfg and returns one
+// result per case, in order. Each file is placed under its case's source and
+// matched with the rules that apply there; nothing on disk is read. It
+// returns an error when a case names a source cfg does not have or expects a
+// rule that does not apply to its source.
+func Run(suite *Suite, cfg *config.Config) ([]Result, error) {
+	sources := cfg.AllSources()
+	engines := make([]*rules.Engine, len(sources))
+	known := make([]map[string]bool, len(sources))
+	for i, src := range sources {
+		engine, err := rules.NewEngine(cfg.RulesFor(src))
+		if err != nil {
+			return nil, fmt.Errorf("building rule engine for %s: %w", src.Path, err)
+		}
+		engines[i] = engine
+		known[i] = map[string]bool{internal.Unmatched: true}
+		for _, r := range engine.Rules() {
+			known[i][r.Name] = true
+		}
+	}
+
+	var errs []error
+	picked := make([]int, len(suite.Cases))
+	for i, c := range suite.Cases {
+		j := sourceIndex(sources, c.Source)
+		picked[i] = j
+		switch {
+		case j < 0:
+			errs = append(errs, fmt.Errorf("case %d (%s): source %q is not in the config", i+1, c.File, c.Source))
+		case !known[j][c.Expect]:
+			errs = append(errs, fmt.Errorf("case %d (%s): expected rule %q does not apply to source %s", i+1, c.File, c.Expect, sources[j].Path))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]Result, 0, len(suite.Cases))
+	for i, c := range suite.Cases {
+		j := picked[i]
+		fi, err := c.FileInfo(sources[j].Path, now)
+		if err != nil {
+			return nil, fmt.Errorf("case %d (%s): %w", i+1, c.File, err)
+		}
+
+		got := internal.Unmatched
+		if r := engines[j].Match(fi); r != nil {
+			got = r.Name
+		}
+		results = append(results, Result{Case: c, Got: got, Passed: got == c.Expect})
+	}
+	return results, nil
+}
+
+// sourceIndex returns the index of the source whose path is path, after
+// expanding both, or 0 for an empty path. It returns -1 when no source
+// matches.
+func sourceIndex(sources []config.SourceConfig, path string) int {
+	if path == "" {
+		return 0
+	}
+	want := cleanPath(path)
+	for i, src := range sources {
+		if cleanPath(src.Path) == want {
+			return i
+		}
+	}
+	return -1
+}
+
+// cleanPath expands and cleans path so equivalent spellings compare equal.
+func cleanPath(path string) string {
+	if expanded, err := config.ExpandPath(path); err == nil {
+		path = expanded
+	}
+	return filepath.Clean(path)
+}
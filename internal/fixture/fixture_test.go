@@ -0,0 +1,246 @@
+package fixture
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/forg/internal/config"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantError string
+	}{
+		{"no cases", "cases: []\n", "at least one case is required"},
+		{"unknown key", "cases:\n  - file: a.jpg\n    expext: images\n", "field expext not found"},
+		{"missing file", "cases:\n  - expect: images\n", "file is required"},
+		{"missing expect", "cases:\n  - file: a.jpg\n", "expect is required"},
+		{"bad size", "cases:\n  - file: a.jpg\n    size: big\n    expect: images\n", "invalid size"},
+		{"size and content", "cases:\n  - file: a.jpg\n    size: 1KB\n    content: hi\n    expect: images\n", "mutually exclusive"},
+		{"bad mtime", "cases:\n  - file: a.jpg\n    mtime: yesterday\n    expect: images\n", "invalid mtime"},
+		{"mtime and age", "cases:\n  - file: a.jpg\n    mtime: 2024-01-01\n    age: 3d\n    expect: images\n", "mutually exclusive"},
+		{"bad type", "cases:\n  - file: a.jpg\n    type: pipe\n    expect: images\n", "invalid type"},
+		{"escapes source", "cases:\n  - file: ../a.jpg\n    expect: images\n", "relative to the source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("Parse() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestParse_CollectsErrors(t *testing.T) {
+	_, err := Parse([]byte("cases:\n  - file: a.jpg\n  - expect: x\n  - file: b.jpg\n    size: huge\n    expect: x\n"))
+	if err == nil {
+		t.Fatal("Parse() expected error, got nil")
+	}
+	for _, want := range []string{"case 1 (a.jpg): expect is required", "case 2 (): file is required", "case 3 (b.jpg): invalid size"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse() error = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestCase_FileInfo(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		c    Case
+		want func(t *testing.T, path string, size int64, mod time.Time, depth int, ext string)
+	}{
+		{
+			name: "defaults",
+			c:    Case{File: "Photo.JPG", Expect: "x"},
+			want: func(t *testing.T, path string, size int64, mod time.Time, depth int, ext string) {
+				if path != filepath.Join("/src", "Photo.JPG") || size != 0 || !mod.Equal(now) || depth != 1 || ext != ".jpg" {
+					t.Errorf("got path %q size %d mtime %v depth %d ext %q", path, size, mod, depth, ext)
+				}
+			},
+		},
+		{
+			name: "content sets size",
+			c:    Case{File: "sub/notes.txt", Content: "hello", Expect: "x"},
+			want: func(t *testing.T, _ string, size int64, _ time.Time, depth int, _ string) {
+				if size != 5 || depth != 2 {
+					t.Errorf("got size %d depth %d, want 5 and 2", size, depth)
+				}
+			},
+		},
+		{
+			name: "size and age",
+			c:    Case{File: "movie.mp4", Size: "2GB", Age: "30d", Expect: "x"},
+			want: func(t *testing.T, _ string, size int64, mod time.Time, _ int, _ string) {
+				if size != 2*1024*1024*1024 || !mod.Equal(now.AddDate(0, 0, -30)) {
+					t.Errorf("got size %d mtime %v", size, mod)
+				}
+			},
+		},
+		{
+			name: "mtime",
+			c:    Case{File: "old.log", MTime: "2024-01-31", Expect: "x"},
+			want: func(t *testing.T, _ string, _ int64, mod time.Time, _ int, _ string) {
+				if mod.Year() != 2024 || mod.Month() != time.January || mod.Day() != 31 {
+					t.Errorf("got mtime %v", mod)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi, err := tt.c.FileInfo("/src", now)
+			if err != nil {
+				t.Fatalf("FileInfo() error: %v", err)
+			}
+			tt.want(t, fi.Path, fi.Size, fi.ModTime, fi.Depth, fi.Extension)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := &config.Config{
+		Source: "/src",
+		Rules: []config.RuleConfig{
+			{Name: "big-videos", Match: config.MatchConfig{Extensions: []string{".mp4"}, MinSize: "1GB"}, Destination: "/tmp/big"},
+			{Name: "videos", Match: config.MatchConfig{Extensions: []string{".mp4"}}, Destination: "/tmp/videos"},
+			{Name: "old-logs", Match: config.MatchConfig{Pattern: "*.log", OlderThan: "30d"}, Destination: "/tmp/logs"},
+		},
+	}
+
+	suite, err := Parse([]byte(`cases:
+  - file: clip.mp4
+    size: 10MB
+    expect: videos
+  - file: film.mp4
+    size: 4GB
+    expect: big-videos
+  - file: app.log
+    age: 60d
+    expect: old-logs
+  - file: today.log
+    expect: unmatched
+  - file: wrong.mp4
+    expect: big-videos
+`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	results, err := Run(suite, cfg)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	want := []struct {
+		got    string
+		passed bool
+	}{
+		{"videos", true},
+		{"big-videos", true},
+		{"old-logs", true},
+		{"unmatched", true},
+		{"videos", false},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Run() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Got != w.got || results[i].Passed != w.passed {
+			t.Errorf("case %d: got %q passed %v, want %q passed %v", i+1, results[i].Got, results[i].Passed, w.got, w.passed)
+		}
+	}
+}
+
+func TestRun_UnknownRule(t *testing.T) {
+	cfg := &config.Config{
+		Source: "/src",
+		Rules: []config.RuleConfig{
+			{Name: "images", Match: config.MatchConfig{Extensions: []string{".jpg"}}, Destination: "/tmp/images"},
+		},
+	}
+
+	suite := &Suite{Cases: []Case{{File: "a.jpg", Expect: "imgs"}}}
+	if _, err := Run(suite, cfg); err == nil || !strings.Contains(err.Error(), `expected rule "imgs" does not apply to source /src`) {
+		t.Errorf("Run() error = %v, want unknown rule error", err)
+	}
+}
+
+func TestRun_Sources(t *testing.T) {
+	cfg := &config.Config{
+		Sources: []config.SourceConfig{
+			{Path: "/downloads"},
+			{
+				Path:  "/scans/",
+				Rules: []config.RuleConfig{{Name: "scans", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: "/tmp/scans"}},
+				Use:   []string{"docs"},
+			},
+		},
+		Rules: []config.RuleConfig{
+			{Name: "docs", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: "/tmp/docs"},
+		},
+	}
+
+	suite := &Suite{Cases: []Case{
+		{File: "a.pdf", Expect: "docs"},
+		{File: "b.pdf", Source: "/scans", Expect: "scans"},
+		{File: "c.pdf", Source: "/downloads", Expect: "docs"},
+	}}
+	results, err := Run(suite, cfg)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	for i, r := range results {
+		if !r.Passed {
+			t.Errorf("case %d: got %q, want %q", i+1, r.Got, r.Case.Expect)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		c         Case
+		wantError string
+	}{
+		{"unknown source", Case{File: "a.pdf", Source: "/elsewhere", Expect: "docs"}, `source "/elsewhere" is not in the config`},
+		{"rule of another source", Case{File: "a.pdf", Expect: "scans"}, `expected rule "scans" does not apply to source /downloads`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(&Suite{Cases: []Case{tt.c}}, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Run() error = %v, want an error containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".forg.test.yaml")
+	if err := os.WriteFile(path, []byte("cases:\n  - file: a.jpg\n    expect: images\n"), 0o600); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	suite, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(suite.Cases) != 1 || suite.Cases[0].File != "a.jpg" {
+		t.Errorf("Load() = %+v", suite)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() expected error for a missing file")
+	}
+}
@@ -0,0 +1,224 @@
+// Package ignore implements gitignore-style exclusion patterns used by the
+// scanner for config "exclude" entries and .forgignore files.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a single compiled exclusion pattern.
+type Pattern struct {
+	raw     string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// Compile parses a single gitignore-style pattern. Supported syntax:
+//
+//   - "*", "?" and "[...]" match within a single path segment
+//   - "**" matches across segments ("**/x", "x/**", "a/**/b")
+//   - a leading "!" re-includes paths excluded by an earlier pattern
+//   - a trailing "/" restricts the pattern to directories
+//   - a pattern containing "/" (other than a trailing one) is anchored to
+//     the directory the pattern belongs to; otherwise it matches at any depth
+func Compile(pattern string) (*Pattern, error) {
+	p := &Pattern{raw: pattern}
+
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		p.negate = true
+		pattern = pattern[1:]
+	case strings.HasPrefix(pattern, `\!`), strings.HasPrefix(pattern, `\#`):
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("invalid pattern %q: empty", p.raw)
+	}
+
+	expr, err := translate(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", p.raw, err)
+	}
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", p.raw, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// String returns the pattern as originally written.
+func (p *Pattern) String() string { return p.raw }
+
+// Negate reports whether the pattern re-includes matching paths.
+func (p *Pattern) Negate() bool { return p.negate }
+
+// Match reports whether rel, a slash-separated path relative to the
+// pattern's base directory, matches the pattern.
+func (p *Pattern) Match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return p.re.MatchString(rel)
+}
+
+// translate converts a glob pattern into an (unanchored) regular expression.
+func translate(pattern string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**") && (i == 0 || pattern[i-1] == '/'):
+			switch {
+			case i+2 == len(pattern):
+				b.WriteString(".*")
+				i++
+			case pattern[i+2] == '/':
+				b.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				b.WriteString("[^/]*")
+				i++
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			if class == "" || class == "^" {
+				return "", fmt.Errorf("empty character class")
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\':
+			if i+1 == len(pattern) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return b.String(), nil
+}
+
+// List is an ordered set of patterns rooted at Base.
+type List struct {
+	Base     string
+	Patterns []*Pattern
+}
+
+// NewList compiles patterns rooted at base.
+func NewList(base string, patterns []string) (*List, error) {
+	l := &List{Base: base}
+	for _, raw := range patterns {
+		p, err := Compile(raw)
+		if err != nil {
+			return nil, err
+		}
+		l.Patterns = append(l.Patterns, p)
+	}
+	return l, nil
+}
+
+// Load reads an ignore file named name from dir. It returns nil and no error
+// when the file does not exist. Blank lines and lines starting with "#" are
+// skipped, and unescaped trailing spaces are trimmed.
+func Load(dir, name string) (*List, error) {
+	path := filepath.Join(dir, name)
+	f, err := os.Open(path) //nolint:gosec // path is built from the scanned tree
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := trimTrailingSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := Compile(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return NewList(dir, lines)
+}
+
+// trimTrailingSpace removes trailing spaces that are not escaped with "\".
+func trimTrailingSpace(s string) string {
+	s = strings.TrimRight(s, "\r")
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// Stack evaluates lists from the outermost directory to the innermost, so
+// patterns in deeper ignore files take precedence. Within the stack the last
+// matching pattern decides whether a path is ignored.
+type Stack []*List
+
+// Push returns a new Stack with l appended. A nil list returns s unchanged.
+func (s Stack) Push(l *List) Stack {
+	if l == nil {
+		return s
+	}
+	out := make(Stack, len(s), len(s)+1)
+	copy(out, s)
+	return append(out, l)
+}
+
+// Ignored reports whether path should be excluded.
+func (s Stack) Ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, l := range s {
+		rel, err := filepath.Rel(l.Base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range l.Patterns {
+			if p.Match(rel, isDir) {
+				ignored = !p.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,128 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		isDir   bool
+		want    bool
+	}{
+		{"node_modules", "node_modules", true, true},
+		{"node_modules", "a/b/node_modules", true, true},
+		{"*.log", "app.log", false, true},
+		{"*.log", "logs/app.log", false, true},
+		{"*.log", "app.txt", false, false},
+		{"keep/", "keep", true, true},
+		{"keep/", "keep", false, false},
+		{"/top.txt", "top.txt", false, true},
+		{"/top.txt", "sub/top.txt", false, false},
+		{"docs/*.md", "docs/readme.md", false, true},
+		{"docs/*.md", "docs/deep/readme.md", false, false},
+		{"**/build", "x/y/build", true, true},
+		{"**/build", "build", true, true},
+		{"cache/**", "cache/a/b", false, true},
+		{"cache/**", "cache", true, false},
+		{"a/**/z", "a/z", false, true},
+		{"a/**/z", "a/b/c/z", false, true},
+		{"file?.txt", "file1.txt", false, true},
+		{"file?.txt", "file10.txt", false, false},
+		{"[ab].txt", "a.txt", false, true},
+		{"[!ab].txt", "a.txt", false, false},
+		{"[!ab].txt", "c.txt", false, true},
+		{`\#literal`, "#literal", false, true},
+		{"photo.jpg", "photoxjpg", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			p, err := Compile(tt.pattern)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.pattern, err)
+			}
+			if got := p.Match(tt.rel, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, pattern := range []string{"", "/", "!", "[abc", `trailing\`, "[]"} {
+		t.Run(pattern, func(t *testing.T) {
+			if _, err := Compile(pattern); err == nil {
+				t.Errorf("Compile(%q) expected error, got nil", pattern)
+			}
+		})
+	}
+}
+
+func TestStack_Ignored(t *testing.T) {
+	root := "/src"
+	outer, err := NewList(root, []string{"*.tmp", "keep/"})
+	if err != nil {
+		t.Fatalf("NewList() error = %v", err)
+	}
+	inner, err := NewList("/src/sub", []string{"!important.tmp"})
+	if err != nil {
+		t.Fatalf("NewList() error = %v", err)
+	}
+
+	stack := Stack{outer}.Push(inner)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"/src/a.tmp", false, true},
+		{"/src/sub/a.tmp", false, true},
+		{"/src/sub/important.tmp", false, false},
+		{"/src/important.tmp", false, true},
+		{"/src/keep", true, true},
+		{"/src/a.txt", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := stack.Ignored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	content := "# comment\n\n*.bak   \n!keep.bak\n"
+	if err := os.WriteFile(filepath.Join(dir, ".forgignore"), []byte(content), 0o600); err != nil {
+		t.Fatalf("writing ignore file: %v", err)
+	}
+
+	list, err := Load(dir, ".forgignore")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(list.Patterns) != 2 {
+		t.Fatalf("expected 2 patterns, got %d", len(list.Patterns))
+	}
+	if list.Patterns[0].String() != "*.bak" {
+		t.Errorf("Patterns[0] = %q, want %q", list.Patterns[0].String(), "*.bak")
+	}
+	if !list.Patterns[1].Negate() {
+		t.Error("expected Patterns[1] to be a negation")
+	}
+
+	missing, err := Load(t.TempDir(), ".forgignore")
+	if err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+	if missing != nil {
+		t.Error("expected nil list for missing file")
+	}
+}
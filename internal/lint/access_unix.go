@@ -0,0 +1,13 @@
+//go:build unix
+
+package lint
+
+import "syscall"
+
+// wOK is the access(2) mode bit that asks for write permission.
+const wOK = 0x2
+
+// writable reports whether the current user may create entries in dir.
+func writable(dir string) bool {
+	return syscall.Access(dir, wOK) == nil
+}
@@ -0,0 +1,169 @@
+// Package lint finds likely mistakes in a configuration that is otherwise
+// valid: rules that can never match and destinations that will cause trouble
+// when files are moved.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/rules"
+)
+
+// Check returns a warning for each likely mistake in cfg, which must already
+// have passed validation. Each source is checked against the rules that apply
+// to it, and a warning shared by several sources is reported once. It only
+// inspects the file system; nothing is created or moved.
+func Check(cfg *config.Config) ([]string, error) {
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, src := range cfg.AllSources() {
+		found, err := checkSource(src.Path, cfg.RulesFor(src))
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range found {
+			if !seen[w] {
+				seen[w] = true
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// checkSource returns the warnings for the rules cfgRules applied to files
+// in the source directory src.
+func checkSource(src string, cfgRules []config.RuleConfig) ([]string, error) {
+	engine, err := rules.NewEngine(cfgRules)
+	if err != nil {
+		return nil, fmt.Errorf("building rules engine: %w", err)
+	}
+
+	var warnings []string
+	warnings = append(warnings, duplicateNames(cfgRules)...)
+	warnings = append(warnings, contradictions(cfgRules)...)
+	warnings = append(warnings, shadowed(engine.Rules())...)
+
+	source, err := config.ExpandPath(src)
+	if err != nil {
+		return nil, fmt.Errorf("expanding source path: %w", err)
+	}
+	source, err = filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("resolving source path: %w", err)
+	}
+	for _, r := range engine.Rules() {
+		warnings = append(warnings, destination(source, r)...)
+	}
+
+	return warnings, nil
+}
+
+// duplicateNames reports rules that reuse the name of an earlier rule, which
+// makes previews, logs and undo records ambiguous.
+func duplicateNames(cfgRules []config.RuleConfig) []string {
+	var warnings []string
+	first := make(map[string]int)
+	for i, r := range cfgRules {
+		if j, ok := first[r.Name]; ok {
+			warnings = append(warnings, fmt.Sprintf("rule %q: name is also used by rule %d", r.Name, j))
+			continue
+		}
+		first[r.Name] = i
+	}
+	return warnings
+}
+
+// contradictions reports rules whose criteria exclude each other, so that no
+// file can satisfy them all.
+func contradictions(cfgRules []config.RuleConfig) []string {
+	var warnings []string
+	for _, r := range cfgRules {
+		m := r.Match
+		if m.MinSize != "" && m.MaxSize != "" {
+			minSize, minErr := config.ParseSize(m.MinSize)
+			maxSize, maxErr := config.ParseSize(m.MaxSize)
+			if minErr == nil && maxErr == nil && minSize > maxSize {
+				warnings = append(warnings, fmt.Sprintf("rule %q can never match: min_size %s is larger than max_size %s", r.Name, m.MinSize, m.MaxSize))
+			}
+		}
+		if m.OlderThan != "" && m.NewerThan != "" {
+			older, olderErr := config.ParseDuration(m.OlderThan)
+			newer, newerErr := config.ParseDuration(m.NewerThan)
+			if olderErr == nil && newerErr == nil && older >= newer {
+				warnings = append(warnings, fmt.Sprintf("rule %q can never match: older_than %s is not shorter than newer_than %s", r.Name, m.OlderThan, m.NewerThan))
+			}
+		}
+	}
+	return warnings
+}
+
+// shadowed reports rules that can never win because an earlier rule matches
+// every file they match.
+func shadowed(all []rules.Rule) []string {
+	var warnings []string
+	for j := range all {
+		for i := range j {
+			if rules.Shadows(&all[i], &all[j]) {
+				warnings = append(warnings, fmt.Sprintf("rule %q can never match: every file it matches is taken by earlier rule %q", all[j].Name, all[i].Name))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// destination reports problems with where r moves files: a destination
+// inside the source, a missing parent directory, or a directory that cannot
+// be written. source must be absolute.
+func destination(source string, r rules.Rule) []string {
+	dest, err := filepath.Abs(r.Destination)
+	if err != nil {
+		return nil
+	}
+
+	var warnings []string
+	if config.IsWithin(dest, source) {
+		warnings = append(warnings, fmt.Sprintf("rule %q: destination %s is inside the source directory; files sorted there are excluded from later scans", r.Name, r.Destination))
+	}
+
+	info, err := os.Stat(dest)
+	switch {
+	case err == nil && !info.IsDir():
+		return append(warnings, fmt.Sprintf("rule %q: destination %s is not a directory", r.Name, r.Destination))
+	case err == nil:
+		if !writable(dest) {
+			warnings = append(warnings, fmt.Sprintf("rule %q: destination %s is not writable", r.Name, r.Destination))
+		}
+		return warnings
+	case !errors.Is(err, fs.ErrNotExist):
+		return append(warnings, fmt.Sprintf("rule %q: destination %s: %v", r.Name, r.Destination, err))
+	}
+
+	parent := filepath.Dir(dest)
+	if _, err := os.Stat(parent); err != nil {
+		warnings = append(warnings, fmt.Sprintf("rule %q: parent directory %s of destination does not exist", r.Name, parent))
+	}
+
+	// The destination is created on first use, which needs write access to
+	// its nearest existing ancestor.
+	for dir := parent; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil {
+			if !info.IsDir() {
+				warnings = append(warnings, fmt.Sprintf("rule %q: destination %s cannot be created: %s is not a directory", r.Name, r.Destination, dir))
+			} else if !writable(dir) {
+				warnings = append(warnings, fmt.Sprintf("rule %q: destination %s cannot be created: %s is not writable", r.Name, r.Destination, dir))
+			}
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/forg/internal/config"
+)
+
+func TestCheck(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+
+	images := config.MatchConfig{Extensions: []string{".jpg", ".png"}}
+
+	tests := []struct {
+		name  string
+		rules []config.RuleConfig
+		want  []string
+	}{
+		{
+			name: "clean config",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: outDir},
+				{Name: "docs", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: outDir},
+			},
+		},
+		{
+			name: "duplicate names",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: outDir},
+				{Name: "images", Match: config.MatchConfig{Extensions: []string{".gif"}}, Destination: outDir},
+			},
+			want: []string{`rule "images": name is also used by rule 0`},
+		},
+		{
+			name: "shadowed rule",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: outDir},
+				{Name: "big-jpegs", Match: config.MatchConfig{Extensions: []string{".jpg"}, MinSize: "10MB"}, Destination: outDir},
+			},
+			want: []string{`rule "big-jpegs" can never match: every file it matches is taken by earlier rule "images"`},
+		},
+		{
+			name: "contradictory sizes",
+			rules: []config.RuleConfig{
+				{Name: "odd", Match: config.MatchConfig{MinSize: "1GB", MaxSize: "1MB"}, Destination: outDir},
+			},
+			want: []string{`rule "odd" can never match: min_size 1GB is larger than max_size 1MB`},
+		},
+		{
+			name: "contradictory ages",
+			rules: []config.RuleConfig{
+				{Name: "odd", Match: config.MatchConfig{OlderThan: "30d", NewerThan: "1w"}, Destination: outDir},
+			},
+			want: []string{`rule "odd" can never match: older_than 30d is not shorter than newer_than 1w`},
+		},
+		{
+			name: "age window",
+			rules: []config.RuleConfig{
+				{Name: "recent", Match: config.MatchConfig{OlderThan: "1w", NewerThan: "30d"}, Destination: outDir},
+			},
+		},
+		{
+			name: "missing destination parent",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: filepath.Join(outDir, "missing", "Images")},
+			},
+			want: []string{`rule "images": parent directory ` + filepath.Join(outDir, "missing") + ` of destination does not exist`},
+		},
+		{
+			name: "destination parent exists",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: filepath.Join(outDir, "Images")},
+			},
+		},
+		{
+			name: "destination inside source",
+			rules: []config.RuleConfig{
+				{Name: "images", Match: images, Destination: filepath.Join(srcDir, "Images")},
+			},
+			want: []string{`rule "images": destination ` + filepath.Join(srcDir, "Images") + ` is inside the source directory`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Source: srcDir, Rules: tt.rules}
+			got, err := Check(cfg)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Check() = %q, want %d warning(s)", got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				if !strings.Contains(got[i], w) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, got[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_Unwritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission checks do not apply to root")
+	}
+
+	srcDir := t.TempDir()
+	locked := filepath.Join(t.TempDir(), "locked")
+	if err := os.Mkdir(locked, 0o500); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+
+	cfg := &config.Config{Source: srcDir, Rules: []config.RuleConfig{
+		{Name: "existing", Match: config.MatchConfig{Pattern: "*.a"}, Destination: locked},
+		{Name: "new", Match: config.MatchConfig{Pattern: "*.b"}, Destination: filepath.Join(locked, "New")},
+	}}
+	got, err := Check(cfg)
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	want := []string{
+		`rule "existing": destination ` + locked + ` is not writable`,
+		`rule "new": destination ` + filepath.Join(locked, "New") + ` cannot be created: ` + locked + ` is not writable`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Check() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
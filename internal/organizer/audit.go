@@ -0,0 +1,166 @@
+package organizer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/progress"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// auditExamples is the number of example paths kept for each extension
+// group of unmatched files.
+const auditExamples = 3
+
+// Coverage summarises how well the rules cover the files in the source: the
+// files no rule matches, grouped by extension, and the files each rule wins.
+type Coverage struct {
+	Scanned   int              `json:"scanned"`
+	Matched   int              `json:"matched"`
+	Unmatched []ExtensionGroup `json:"unmatched"`
+	// Rules holds one entry per configured rule, in config order. A rule
+	// with no files never wins, either because nothing matches it or because
+	// earlier rules take everything it matches.
+	Rules      []RuleCoverage      `json:"rules"`
+	ScanErrors []scanner.ScanError `json:"-"`
+}
+
+// ExtensionGroup totals the unmatched files sharing an extension.
+type ExtensionGroup struct {
+	// Extension is lower-case with its leading dot, or empty for files
+	// without one.
+	Extension string   `json:"extension"`
+	Files     int      `json:"files"`
+	Bytes     int64    `json:"bytes"`
+	Examples  []string `json:"examples"`
+}
+
+// RuleCoverage totals the files a rule wins.
+type RuleCoverage struct {
+	Name string `json:"name"`
+	// Source is the path of the source that defines the rule in its own
+	// rules list, or empty for a top-level rule. Sources may reuse a rule
+	// name, so only Name and Source together identify a row.
+	Source string `json:"source,omitempty"`
+	Files  int    `json:"files"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// Label returns the rule name, followed by its source for a rule defined by
+// a single source.
+func (r RuleCoverage) Label() string {
+	if r.Source == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, r.Source)
+}
+
+// UnmatchedFiles returns the total number of files no rule matches.
+func (c *Coverage) UnmatchedFiles() int {
+	return c.Scanned - c.Matched
+}
+
+// DeadRules returns the labels of rules that won no files, in config order.
+func (c *Coverage) DeadRules() []string {
+	var dead []string
+	for _, r := range c.Rules {
+		if r.Files == 0 {
+			dead = append(dead, r.Label())
+		}
+	}
+	return dead
+}
+
+// Audit scans the sources of cfg as Run would with opts and evaluates every
+// file against the rules without planning or moving anything. Unmatched
+// groups are ordered by file count, largest first. Scan errors in tolerant
+// mode are collected in Coverage.ScanErrors; any other scan error stops the
+// audit and is returned with the coverage so far.
+func Audit(ctx context.Context, cfg *config.Config, opts Options) (*Coverage, error) {
+	sources, err := prepareSources(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Top-level rules shared by several sources are counted once, in the
+	// order they are first seen. A source's own rules come first in its
+	// engine and get rows of their own, even when another source defines a
+	// rule with the same name.
+	cov := &Coverage{}
+	index := make(map[RuleCoverage]int)
+	ruleIndex := make([][]int, len(sources))
+	for i, src := range cfg.AllSources() {
+		for j, r := range sources[i].engine.Rules() {
+			key := RuleCoverage{Name: r.Name}
+			if j < len(src.Rules) {
+				key.Source = src.Path
+			}
+			k, ok := index[key]
+			if !ok {
+				k = len(cov.Rules)
+				index[key] = k
+				cov.Rules = append(cov.Rules, key)
+			}
+			ruleIndex[i] = append(ruleIndex[i], k)
+		}
+	}
+	groups := make(map[string]*ExtensionGroup)
+
+	for i, s := range sources {
+		files := s.scanner.Files(ctx, s.dir)
+		if opts.Progress != nil {
+			files = observeScan(files, opts.Progress)
+		}
+		for f, err := range files {
+			if err != nil {
+				var se scanner.ScanError
+				if errors.As(err, &se) {
+					cov.ScanErrors = append(cov.ScanErrors, se)
+					continue
+				}
+				cov.finish(groups)
+				return cov, fmt.Errorf("scanning source directory: %w", err)
+			}
+
+			cov.Scanned++
+			if r := s.engine.MatchIndex(f); r >= 0 {
+				cov.Matched++
+				cov.Rules[ruleIndex[i][r]].Files++
+				cov.Rules[ruleIndex[i][r]].Bytes += f.Size
+				continue
+			}
+
+			g, ok := groups[f.Extension]
+			if !ok {
+				g = &ExtensionGroup{Extension: f.Extension}
+				groups[f.Extension] = g
+			}
+			g.Files++
+			g.Bytes += f.Size
+			if len(g.Examples) < auditExamples {
+				g.Examples = append(g.Examples, f.Path)
+			}
+		}
+	}
+	if opts.Progress != nil {
+		opts.Progress(progress.Event{Kind: progress.ScanDone})
+	}
+
+	cov.finish(groups)
+	return cov, nil
+}
+
+// finish stores groups in c ordered by file count, then extension.
+func (c *Coverage) finish(groups map[string]*ExtensionGroup) {
+	c.Unmatched = make([]ExtensionGroup, 0, len(groups))
+	for _, g := range groups {
+		c.Unmatched = append(c.Unmatched, *g)
+	}
+	slices.SortFunc(c.Unmatched, func(a, b ExtensionGroup) int {
+		return cmp.Or(cmp.Compare(b.Files, a.Files), cmp.Compare(a.Extension, b.Extension))
+	})
+}
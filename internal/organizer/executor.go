@@ -1,12 +1,18 @@
 package organizer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/progress"
+	"github.com/devaloi/forg/internal/scanner"
 )
 
 // FileSystem abstracts file-system operations so that the executor can be
@@ -15,6 +21,9 @@ type FileSystem interface {
 	Rename(oldpath, newpath string) error
 	MkdirAll(path string, perm os.FileMode) error
 	Stat(path string) (os.FileInfo, error)
+	Readlink(path string) (string, error)
+	Symlink(oldname, newname string) error
+	Remove(path string) error
 }
 
 // OSFileSystem implements FileSystem using the standard os package.
@@ -28,8 +37,19 @@ func (OSFileSystem) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
-// Stat returns the FileInfo for the named file.
-func (OSFileSystem) Stat(path string) (os.FileInfo, error) { return os.Stat(path) }
+// Stat returns the FileInfo for the named file. A symbolic link is described
+// itself rather than its target, so an existing link at a destination counts
+// as a conflict even when it dangles.
+func (OSFileSystem) Stat(path string) (os.FileInfo, error) { return os.Lstat(path) }
+
+// Readlink returns the destination of the named symbolic link.
+func (OSFileSystem) Readlink(path string) (string, error) { return os.Readlink(path) }
+
+// Symlink creates newname as a symbolic link to oldname.
+func (OSFileSystem) Symlink(oldname, newname string) error { return os.Symlink(oldname, newname) }
+
+// Remove removes the named file or empty directory.
+func (OSFileSystem) Remove(path string) error { return os.Remove(path) }
 
 // Executor moves files according to a plan, handling conflicts and logging.
 type Executor struct {
@@ -37,6 +57,34 @@ type Executor struct {
 	conflict string
 	verbose  bool
 	logger   func(string, ...interface{})
+	jobs     int
+	progress func(progress.Event)
+	// keepResults stores every Result in Report.Results; onResult, if set,
+	// receives each one in plan order as it is decided.
+	keepResults bool
+	onResult    func(Result)
+
+	// mu guards dirLocks and claims, which let concurrent workers resolve
+	// conflicts one destination directory at a time.
+	mu       sync.Mutex
+	dirLocks map[string]*sync.Mutex
+	claims   map[string]bool
+
+	// linkMu guards movedTo and links, which keep moved symbolic links
+	// pointing at their targets when the targets move in the same run.
+	// movedTo maps the source of every completed move to its final path;
+	// links maps a target that has not moved yet to the moved links that
+	// point at it.
+	linkMu  sync.Mutex
+	movedTo map[string]string
+	links   map[string][]movedLink
+}
+
+// movedLink is a symbolic link at path whose target may still move. abs
+// records whether the link was written with an absolute target.
+type movedLink struct {
+	path string
+	abs  bool
 }
 
 // NewExecutor creates an Executor that uses the real OS file system.
@@ -55,77 +103,430 @@ func NewExecutorWithFS(fs FileSystem, conflict string, verbose bool, logger func
 		conflict: conflict,
 		verbose:  verbose,
 		logger:   logger,
+		jobs:     1,
+		dirLocks: make(map[string]*sync.Mutex),
+		claims:   make(map[string]bool),
+		movedTo:  make(map[string]string),
+		links:    make(map[string][]movedLink),
+
+		keepResults: true,
 	}
 }
 
+// SetJobs sets the number of operations ExecuteStream runs concurrently.
+// Values below 1 are treated as 1. Dry runs are always sequential.
+func (e *Executor) SetJobs(n int) {
+	e.jobs = max(n, 1)
+}
+
+// SetProgress sets a function that receives a Moved, Skipped or Failed event
+// for every operation. With more than one job it is called concurrently.
+func (e *Executor) SetProgress(fn func(progress.Event)) {
+	e.progress = fn
+}
+
+// SetResults controls what happens to the Result of each operation: keep
+// stores them in Report.Results, and the planned operations of a dry run in
+// Report.Operations, both of which grow with the plan; fn, if not nil,
+// receives each one in plan order. Results are kept by default.
+func (e *Executor) SetResults(keep bool, fn func(Result)) {
+	e.keepResults = keep
+	e.onResult = fn
+}
+
 // Execute runs every operation in plan, moving files to their destinations.
 // When dryRun is true no files are moved; the returned report still describes
 // what would happen. The returned UndoEntry slice records every successful
-// move so it can be reversed later.
-func (e *Executor) Execute(plan []MoveOp, dryRun bool) (*Report, []UndoEntry) {
+// move so it can be reversed later. If ctx is cancelled, execution stops
+// before the next operation and Report.Interrupted is set.
+func (e *Executor) Execute(ctx context.Context, plan []MoveOp, dryRun bool) (*Report, []UndoEntry) {
 	report := &Report{DryRun: dryRun}
 	var undoEntries []UndoEntry
 
 	for _, op := range plan {
-		destPath := filepath.Join(op.Destination, filepath.Base(op.Source))
+		if ctx.Err() != nil {
+			report.Interrupted = true
+			break
+		}
+		n := len(report.Results)
+		if entry, ok := e.apply(op, dryRun, report); ok {
+			undoEntries = append(undoEntries, entry)
+		}
+		e.emit(report, n)
+	}
 
-		if dryRun {
-			report.Operations = append(report.Operations, op)
-			report.Moved++
-			if e.verbose {
-				e.logger("[dry-run] %s -> %s (rule: %s)", op.Source, destPath, op.RuleName)
+	return report, undoEntries
+}
+
+// ExecuteStream is the streaming form of Execute: each operation is applied
+// as soon as ops yields it. scanner.ScanError values are recorded in
+// Report.ScanErrors; any other error stops execution and is returned along
+// with the report and undo entries for the moves completed so far.
+// Cancelling ctx lets in-flight operations finish, sets Report.Interrupted
+// and returns ctx's error.
+//
+// With more than one job, operations run on a pool of workers. Conflict
+// resolution is serialised per destination directory, and the report and
+// undo entries are assembled in plan order as operations finish.
+func (e *Executor) ExecuteStream(ctx context.Context, ops iter.Seq2[MoveOp, error], dryRun bool) (*Report, []UndoEntry, error) {
+	if e.jobs > 1 && !dryRun {
+		return e.executeParallel(ctx, ops)
+	}
+
+	report := &Report{DryRun: dryRun}
+	var undoEntries []UndoEntry
+
+	for op, err := range ops {
+		if ctx.Err() != nil {
+			report.Interrupted = true
+			return report, undoEntries, ctx.Err()
+		}
+		if err != nil {
+			var se scanner.ScanError
+			if errors.As(err, &se) {
+				report.ScanErrors = append(report.ScanErrors, se)
+				continue
 			}
-			continue
+			return report, undoEntries, err
+		}
+		n := len(report.Results)
+		if entry, ok := e.apply(op, dryRun, report); ok {
+			undoEntries = append(undoEntries, entry)
 		}
+		e.emit(report, n)
+	}
+
+	return report, undoEntries, nil
+}
+
+// executeParallel is ExecuteStream for more than one job. Outcomes are
+// merged into the report, and passed on to the result function, in plan
+// order as soon as every earlier operation has finished; only outcomes that
+// finish ahead of an earlier one wait in a reorder buffer.
+func (e *Executor) executeParallel(ctx context.Context, ops iter.Seq2[MoveOp, error]) (*Report, []UndoEntry, error) {
+	type job struct {
+		seq int
+		op  MoveOp
+	}
+	type outcome struct {
+		entry  UndoEntry
+		moved  bool
+		report Report
+	}
+
+	report := &Report{}
+	var (
+		mu          sync.Mutex
+		pending     = make(map[int]outcome)
+		next        int
+		undoEntries []UndoEntry
+		wg          sync.WaitGroup
+	)
 
-		if err := e.fs.MkdirAll(op.Destination, internal.DefaultDirPerms); err != nil {
-			e.logger("error creating directory %s: %v", op.Destination, err)
-			report.Errors++
-			continue
+	// finish records the outcome of operation seq and releases every
+	// outcome that is now next in plan order.
+	finish := func(seq int, o outcome) {
+		mu.Lock()
+		defer mu.Unlock()
+		pending[seq] = o
+		for {
+			d, ok := pending[next]
+			if !ok {
+				return
+			}
+			delete(pending, next)
+			next++
+			e.emit(&d.report, 0)
+			report.merge(&d.report)
+			if d.moved {
+				undoEntries = append(undoEntries, d.entry)
+			}
 		}
+	}
+
+	// A small buffer keeps the scan just ahead of the workers without
+	// letting it run away from them.
+	jobs := make(chan job, e.jobs)
+	for range e.jobs {
+		wg.Go(func() {
+			for j := range jobs {
+				// Queued operations are dropped once cancelled; only the
+				// moves already under way complete. A dropped operation
+				// still finishes, empty, so later ones are not held back.
+				var o outcome
+				if ctx.Err() != nil {
+					mu.Lock()
+					report.Interrupted = true
+					mu.Unlock()
+				} else {
+					o.entry, o.moved = e.apply(j.op, false, &o.report)
+				}
+				finish(j.seq, o)
+			}
+		})
+	}
 
-		finalDest, hadConflict, err := e.resolveConflict(destPath)
+	var streamErr error
+	interrupted := false
+	seq := 0
+	for op, err := range ops {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
 		if err != nil {
-			e.logger("error resolving conflict for %s: %v", destPath, err)
-			report.Errors++
-			continue
+			var se scanner.ScanError
+			if errors.As(err, &se) {
+				mu.Lock()
+				report.ScanErrors = append(report.ScanErrors, se)
+				mu.Unlock()
+				continue
+			}
+			streamErr = err
+			break
+		}
+		select {
+		case jobs <- job{seq: seq, op: op}:
+			seq++
+		case <-ctx.Done():
+			interrupted = true
+		}
+		if interrupted {
+			break
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		if finalDest == "" {
-			// skip strategy
-			report.Skipped++
-			report.Conflicts++
-			if e.verbose {
-				e.logger("skipped %s (conflict at %s)", op.Source, destPath)
-			}
-			continue
+	if interrupted || report.Interrupted {
+		report.Interrupted = true
+		streamErr = ctx.Err()
+	}
+
+	return report, undoEntries, streamErr
+}
+
+// apply performs a single operation and updates report. It returns the undo
+// entry and true when a file was actually moved.
+func (e *Executor) apply(op MoveOp, dryRun bool, report *Report) (UndoEntry, bool) {
+	destPath := filepath.Join(op.Destination, filepath.Base(op.Source))
+
+	if dryRun {
+		if e.keepResults {
+			report.Operations = append(report.Operations, op)
 		}
+		report.Moved++
+		e.record(report, op, destPath, internal.ActionMove, internal.StatusPlanned, nil)
+		e.notify(progress.Moved, op)
+		if e.verbose {
+			e.logger("[dry-run] %s -> %s (rule: %s)", op.Source, destPath, op.RuleName)
+		}
+		return UndoEntry{}, false
+	}
 
-		if hadConflict && e.verbose {
-			e.logger("conflict resolved for %s -> %s", destPath, finalDest)
+	if err := e.fs.MkdirAll(op.Destination, internal.DefaultDirPerms); err != nil {
+		report.Errors++
+		e.record(report, op, destPath, internal.ActionMove, internal.StatusError,
+			fmt.Errorf("creating directory %s: %w", op.Destination, err))
+		e.notify(progress.Failed, op)
+		return UndoEntry{}, false
+	}
+
+	// Resolve and claim the final name while holding the directory lock so
+	// that concurrent workers never pick the same name.
+	unlock := e.lockDir(op.Destination)
+	finalDest, hadConflict, err := e.resolveConflict(destPath)
+	if err == nil && finalDest != "" {
+		e.claim(finalDest, true)
+	}
+	unlock()
+
+	if err != nil {
+		report.Errors++
+		e.record(report, op, destPath, internal.ActionMove, internal.StatusError,
+			fmt.Errorf("resolving conflict: %w", err))
+		e.notify(progress.Failed, op)
+		return UndoEntry{}, false
+	}
+
+	if finalDest == "" {
+		// skip strategy
+		report.Skipped++
+		report.Conflicts++
+		e.record(report, op, destPath, internal.ActionSkip, internal.StatusSkipped, nil)
+		e.notify(progress.Skipped, op)
+		if e.verbose {
+			e.logger("skipped %s (conflict at %s)", op.Source, destPath)
 		}
+		return UndoEntry{}, false
+	}
 
-		if err := e.fs.Rename(op.Source, finalDest); err != nil {
-			e.logger("error moving %s to %s: %v", op.Source, finalDest, err)
-			report.Errors++
-			continue
+	action, status := internal.ActionMove, internal.StatusMoved
+	if hadConflict {
+		action, status = internal.ActionRename, internal.StatusConflict
+		if finalDest == destPath {
+			action = internal.ActionOverwrite
+		}
+		if e.verbose {
+			e.logger("conflict resolved for %s -> %s", destPath, finalDest)
 		}
+	}
 
-		undoEntries = append(undoEntries, UndoEntry{From: op.Source, To: finalDest})
-		report.Moved++
+	linkText, err := e.move(op, finalDest)
+	e.claim(finalDest, false)
+	if err != nil {
+		report.Errors++
+		e.record(report, op, finalDest, action, internal.StatusError, err)
+		e.notify(progress.Failed, op)
+		return UndoEntry{}, false
+	}
 
+	report.Moved++
+	e.record(report, op, finalDest, action, status, nil)
+	e.notify(progress.Moved, op)
+
+	if e.verbose {
+		e.logger("moved %s -> %s (rule: %s)", op.Source, finalDest, op.RuleName)
+	}
+
+	return UndoEntry{From: op.Source, To: finalDest, Link: linkText}, true
+}
+
+// record appends the outcome of op to report. Failures are logged in
+// verbose mode; otherwise they are left to the caller to present from
+// Report.Failures.
+func (e *Executor) record(report *Report, op MoveOp, dest, action, status string, err error) {
+	r := Result{
+		Source:      op.Source,
+		Destination: dest,
+		Rule:        op.RuleName,
+		Action:      action,
+		Status:      status,
+	}
+	if err != nil {
+		r.Error = err.Error()
 		if e.verbose {
-			e.logger("moved %s -> %s (rule: %s)", op.Source, finalDest, op.RuleName)
+			e.logger("error: %s: %v", op.Source, err)
 		}
+		report.Failures = append(report.Failures, OpError{Op: op, Destination: dest, Err: err})
 	}
+	report.Results = append(report.Results, r)
+}
 
-	return report, undoEntries
+// emit passes the results recorded in report from index from onwards to the
+// result function, and drops them again unless results are kept.
+func (e *Executor) emit(report *Report, from int) {
+	if e.onResult != nil {
+		for _, r := range report.Results[from:] {
+			e.onResult(r)
+		}
+	}
+	if !e.keepResults {
+		report.Results = report.Results[:from]
+	}
+}
+
+// notify sends a progress event of kind for op, if progress is enabled.
+func (e *Executor) notify(kind progress.Kind, op MoveOp) {
+	if e.progress != nil {
+		e.progress(progress.Event{Kind: kind, Path: op.Source, Size: op.Size})
+	}
+}
+
+// move moves op.Source to dest. A symbolic link is recreated at dest rather
+// than renamed: a relative target is re-based on the new directory, and a
+// target that this run has moved, or moves later, is followed to its new
+// path, so the link keeps resolving. For a link it returns the original link
+// text, which undo restores.
+func (e *Executor) move(op MoveOp, dest string) (string, error) {
+	if !op.Symlink {
+		if err := e.fs.Rename(op.Source, dest); err != nil {
+			return "", err
+		}
+		e.moved(op.Source, dest)
+		return "", nil
+	}
+
+	text, err := e.fs.Readlink(op.Source)
+	if err != nil {
+		return "", fmt.Errorf("reading link: %w", err)
+	}
+	target := text
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(op.Source), target)
+	}
+
+	// Holding linkMu from here until the link is registered means a target
+	// moving concurrently either is already in movedTo or finds the link.
+	e.linkMu.Lock()
+	defer e.linkMu.Unlock()
+
+	current, targetMoved := e.movedTo[target]
+	if !targetMoved {
+		current = target
+	}
+	if e.conflict == internal.ConflictOverwrite {
+		if err := e.fs.Remove(dest); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("removing existing destination: %w", err)
+		}
+	}
+	abs := filepath.IsAbs(text)
+	if err := e.fs.Symlink(linkTarget(current, dest, abs), dest); err != nil {
+		return "", fmt.Errorf("creating link: %w", err)
+	}
+	if err := e.fs.Remove(op.Source); err != nil {
+		_ = e.fs.Remove(dest)
+		return "", fmt.Errorf("removing original link: %w", err)
+	}
+
+	if !targetMoved {
+		e.links[target] = append(e.links[target], movedLink{path: dest, abs: abs})
+	}
+	e.movedLocked(op.Source, dest)
+	return text, nil
+}
+
+// moved records that source now lives at dest.
+func (e *Executor) moved(source, dest string) {
+	e.linkMu.Lock()
+	defer e.linkMu.Unlock()
+	e.movedLocked(source, dest)
+}
+
+// movedLocked is moved with linkMu held. Links moved earlier in the run
+// that point at source are rewritten to point at dest.
+func (e *Executor) movedLocked(source, dest string) {
+	e.movedTo[source] = dest
+	for _, l := range e.links[source] {
+		err := e.fs.Remove(l.path)
+		if err == nil {
+			err = e.fs.Symlink(linkTarget(dest, l.path, l.abs), l.path)
+		}
+		if err != nil {
+			e.logger("warning: link %s still points at %s: %v", l.path, source, err)
+		}
+	}
+	delete(e.links, source)
+}
+
+// linkTarget returns the text of a link at path that points at target: the
+// absolute target when abs is set, otherwise the target relative to the
+// link's directory.
+func linkTarget(target, path string, abs bool) string {
+	if abs {
+		return target
+	}
+	rel, err := filepath.Rel(filepath.Dir(path), target)
+	if err != nil {
+		return target
+	}
+	return rel
 }
 
 // resolveConflict determines the final destination path when a file already
 // exists at destPath. It applies the executor's conflict strategy.
 func (e *Executor) resolveConflict(destPath string) (string, bool, error) {
-	_, err := e.fs.Stat(destPath)
+	err := e.stat(destPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return destPath, false, nil
@@ -159,8 +560,7 @@ func (e *Executor) findUniqueName(destPath string) (string, error) {
 
 	for i := 1; i <= internal.MaxRenameAttempts; i++ {
 		candidate := filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
-		_, err := e.fs.Stat(candidate)
-		if err != nil {
+		if err := e.stat(candidate); err != nil {
 			if os.IsNotExist(err) {
 				return candidate, nil
 			}
@@ -169,3 +569,42 @@ func (e *Executor) findUniqueName(destPath string) (string, error) {
 	}
 	return "", fmt.Errorf("could not find unique name for %q after %d attempts", destPath, internal.MaxRenameAttempts)
 }
+
+// lockDir locks dir for conflict resolution and returns its unlock function.
+func (e *Executor) lockDir(dir string) func() {
+	e.mu.Lock()
+	l, ok := e.dirLocks[dir]
+	if !ok {
+		l = &sync.Mutex{}
+		e.dirLocks[dir] = l
+	}
+	e.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// claim marks path as taken by an in-flight move, or releases it once the
+// move has finished and the file system reflects the result.
+func (e *Executor) claim(path string, taken bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if taken {
+		e.claims[path] = true
+	} else {
+		delete(e.claims, path)
+	}
+}
+
+// stat reports whether path exists like FileSystem.Stat, additionally
+// treating paths claimed by in-flight moves as existing.
+func (e *Executor) stat(path string) error {
+	e.mu.Lock()
+	claimed := e.claims[path]
+	e.mu.Unlock()
+	if claimed {
+		return nil
+	}
+	_, err := e.fs.Stat(path)
+	return err
+}
@@ -0,0 +1,85 @@
+package organizer
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/rules"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// Explanation describes how Run would handle a single file.
+type Explanation struct {
+	File scanner.FileInfo
+	// Skipped says why the scan would never reach the file. The rules are
+	// still evaluated so the caller can show what would happen otherwise.
+	Skipped string
+	// Traces holds every rule's evaluation of the file, in config order.
+	Traces []rules.Trace
+	// Rule is the first matching rule, or nil when none matches.
+	Rule *rules.Rule
+	// Destination is the path the rule sends the file to, before conflict
+	// handling. Final is where it would actually end up, and is empty when
+	// the conflict strategy skips it.
+	Destination string
+	Final       string
+	// Action is one of the internal.Action* values.
+	Action string
+}
+
+// Explain traces path through the scan options and rules of cfg as Run would
+// with opts, using the source that most closely contains path, and resolves
+// destination conflicts against the current state of the file system.
+// Nothing is moved.
+func Explain(cfg *config.Config, opts Options, path string) (*Explanation, error) {
+	sources, err := prepareSources(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	src := sourceFor(sources, path)
+	engine := src.engine
+
+	file, skipped, err := src.scanner.Describe(src.dir, path)
+	if err != nil {
+		return nil, fmt.Errorf("describing %s: %w", path, err)
+	}
+
+	ex := &Explanation{
+		File:    file,
+		Skipped: skipped,
+		Traces:  engine.Explain(file, time.Now()),
+	}
+
+	for _, t := range ex.Traces {
+		if t.Matched {
+			ex.Rule = t.Rule
+			break
+		}
+	}
+	if ex.Rule == nil {
+		return ex, nil
+	}
+
+	ex.Destination = filepath.Join(ex.Rule.Destination, filepath.Base(file.Path))
+	final, hadConflict, err := NewExecutor(cfg.Conflict, false, nil).resolveConflict(ex.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("resolving conflict: %w", err)
+	}
+	ex.Final = final
+
+	switch {
+	case final == "":
+		ex.Action = internal.ActionSkip
+	case hadConflict && final == ex.Destination:
+		ex.Action = internal.ActionOverwrite
+	case hadConflict:
+		ex.Action = internal.ActionRename
+	default:
+		ex.Action = internal.ActionMove
+	}
+
+	return ex, nil
+}
@@ -1,12 +1,18 @@
 package organizer_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/cache"
 	"github.com/devaloi/forg/internal/config"
 	"github.com/devaloi/forg/internal/organizer"
+	"github.com/devaloi/forg/internal/progress"
 )
 
 func noopLogger(string, ...interface{}) {}
@@ -81,7 +87,7 @@ func TestIntegration_FullPipeline(t *testing.T) {
 		dryOpts := opts
 		dryOpts.DryRun = true
 
-		report, err := organizer.Run(cfg, dryOpts, noopLogger)
+		report, err := organizer.Run(t.Context(), cfg, dryOpts, noopLogger)
 		if err != nil {
 			t.Fatalf("Run(DryRun=true): %v", err)
 		}
@@ -102,7 +108,7 @@ func TestIntegration_FullPipeline(t *testing.T) {
 	})
 
 	t.Run("RealRun", func(t *testing.T) {
-		report, err := organizer.Run(cfg, opts, noopLogger)
+		report, err := organizer.Run(t.Context(), cfg, opts, noopLogger)
 		if err != nil {
 			t.Fatalf("Run(DryRun=false): %v", err)
 		}
@@ -206,7 +212,7 @@ func TestIntegration_EmptySource(t *testing.T) {
 		},
 	}
 
-	report, err := organizer.Run(cfg, organizer.Options{}, noopLogger)
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{}, noopLogger)
 	if err != nil {
 		t.Fatalf("Run on empty source: %v", err)
 	}
@@ -250,7 +256,7 @@ func TestIntegration_NoMatchingRules(t *testing.T) {
 		},
 	}
 
-	report, err := organizer.Run(cfg, organizer.Options{}, noopLogger)
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{}, noopLogger)
 	if err != nil {
 		t.Fatalf("Run with no matching rules: %v", err)
 	}
@@ -296,7 +302,7 @@ func TestIntegration_DestinationAutoCreate(t *testing.T) {
 		},
 	}
 
-	report, err := organizer.Run(cfg, organizer.Options{}, noopLogger)
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{}, noopLogger)
 	if err != nil {
 		t.Fatalf("Run with auto-create destination: %v", err)
 	}
@@ -313,3 +319,731 @@ func TestIntegration_DestinationAutoCreate(t *testing.T) {
 		t.Error("report.pdf should have been moved from source")
 	}
 }
+
+func TestIntegration_RecursiveSkipsDestinationUnderSource(t *testing.T) {
+	fakeHome := t.TempDir()
+	t.Setenv("HOME", fakeHome)
+
+	sourceDir := t.TempDir()
+	destDir := filepath.Join(sourceDir, "Images")
+
+	if err := os.MkdirAll(destDir, 0o750); err != nil {
+		t.Fatalf("creating dest dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "new.jpg"), []byte("new"), 0o600); err != nil {
+		t.Fatalf("creating source file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "sorted.jpg"), []byte("sorted"), 0o600); err != nil {
+		t.Fatalf("creating sorted file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Source:   sourceDir,
+		Conflict: "rename",
+		Rules: []config.RuleConfig{
+			{
+				Name:        "Images",
+				Match:       config.MatchConfig{Extensions: []string{".jpg"}},
+				Destination: destDir,
+			},
+		},
+	}
+
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{Recursive: true}, noopLogger)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if report.Moved != 1 {
+		t.Errorf("expected 1 moved, got %d", report.Moved)
+	}
+	if fileExists(filepath.Join(destDir, "sorted-1.jpg")) {
+		t.Error("already-sorted file should not have been reprocessed")
+	}
+	if !fileExists(filepath.Join(destDir, "new.jpg")) {
+		t.Error("new.jpg should have been moved into the destination")
+	}
+}
+
+func TestIntegration_MultipleSources(t *testing.T) {
+	fakeHome := t.TempDir()
+	t.Setenv("HOME", fakeHome)
+
+	tmpdir := t.TempDir()
+	downloads := filepath.Join(tmpdir, "Downloads")
+	desktop := filepath.Join(tmpdir, "Desktop")
+	imagesDir := filepath.Join(tmpdir, "Images")
+	docsDir := filepath.Join(tmpdir, "Docs")
+
+	for _, name := range []string{
+		"Downloads/a.jpg",
+		"Downloads/notes.pdf",
+		"Downloads/sub/deep.jpg",
+		"Desktop/a.jpg",
+		"Desktop/b.jpg",
+		"Desktop/.hidden.jpg",
+		"Desktop/report.pdf",
+	} {
+		path := filepath.Join(tmpdir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Conflict: "rename",
+		Rules: []config.RuleConfig{
+			{Name: "Images", Match: config.MatchConfig{Extensions: []string{".jpg"}}, Destination: imagesDir},
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: docsDir},
+		},
+		Sources: []config.SourceConfig{
+			{Path: downloads, Recursive: true, Use: []string{"Images"}},
+			{
+				Path:          desktop,
+				IncludeHidden: true,
+				Exclude:       []string{"b.*"},
+				Rules: []config.RuleConfig{
+					{Name: "Desktop PDFs", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: filepath.Join(docsDir, "Desktop")},
+				},
+				Use: []string{"Images"},
+			},
+		},
+	}
+
+	ex, err := organizer.Explain(cfg, organizer.Options{}, filepath.Join(desktop, "report.pdf"))
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if ex.Rule == nil || ex.Rule.Name != "Desktop PDFs" {
+		t.Errorf("Explain picked rule %v, want Desktop PDFs", ex.Rule)
+	}
+
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{}, noopLogger)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if report.Moved != 5 {
+		t.Errorf("expected 5 moved, got %d: %+v", report.Moved, report.Results)
+	}
+	for _, path := range []string{
+		filepath.Join(imagesDir, "a.jpg"),
+		filepath.Join(imagesDir, "a-1.jpg"),
+		filepath.Join(imagesDir, "deep.jpg"),
+		filepath.Join(imagesDir, ".hidden.jpg"),
+		filepath.Join(docsDir, "Desktop", "report.pdf"),
+		filepath.Join(downloads, "notes.pdf"),
+		filepath.Join(desktop, "b.jpg"),
+	} {
+		if !fileExists(path) {
+			t.Errorf("expected %s to exist", path)
+		}
+	}
+
+	if len(report.Collisions) != 1 {
+		t.Fatalf("expected 1 collision, got %+v", report.Collisions)
+	}
+	c := report.Collisions[0]
+	wantFiles := []string{filepath.Join(downloads, "a.jpg"), filepath.Join(desktop, "a.jpg")}
+	if c.Destination != filepath.Join(imagesDir, "a.jpg") || len(c.Files) != 2 || c.Files[0] != wantFiles[0] || c.Files[1] != wantFiles[1] {
+		t.Errorf("collision = %+v, want %s from %v", c, filepath.Join(imagesDir, "a.jpg"), wantFiles)
+	}
+}
+
+func TestIntegration_NestedSources(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	nest := filepath.Join(tmpdir, "nest")
+	inner := filepath.Join(nest, "inner")
+	for _, path := range []string{filepath.Join(nest, "a.pdf"), filepath.Join(inner, "b.pdf")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("pdf"), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	docsDir := filepath.Join(tmpdir, "docs")
+	cfg := &config.Config{
+		Conflict: "skip",
+		Rules: []config.RuleConfig{
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: docsDir},
+		},
+		Sources: []config.SourceConfig{
+			{Path: nest, Recursive: true},
+			{
+				Path:  inner,
+				Rules: []config.RuleConfig{{Name: "Inner", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: filepath.Join(docsDir, "inner")}},
+			},
+		},
+	}
+
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{DryRun: true, KeepResults: true}, noopLogger)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	rules := make(map[string]string)
+	for _, r := range report.Results {
+		if _, dup := rules[r.Source]; dup {
+			t.Errorf("%s planned more than once", r.Source)
+		}
+		rules[r.Source] = r.Rule
+	}
+	if len(rules) != 2 || rules[filepath.Join(inner, "b.pdf")] != "Inner" {
+		t.Errorf("planned %v, want a.pdf once and b.pdf once by the inner source's rule", rules)
+	}
+	if len(report.Collisions) != 0 {
+		t.Errorf("unexpected collisions %+v", report.Collisions)
+	}
+
+	report, err = organizer.Run(t.Context(), cfg, organizer.Options{Jobs: 8}, noopLogger)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Moved != 2 || report.Skipped != 0 {
+		t.Errorf("moved %d and skipped %d, want 2 and 0", report.Moved, report.Skipped)
+	}
+	if !fileExists(filepath.Join(docsDir, "inner", "b.pdf")) {
+		t.Error("expected b.pdf to be moved by the inner source's rule")
+	}
+}
+
+func TestIntegration_TolerantScan(t *testing.T) {
+	fakeHome := t.TempDir()
+	t.Setenv("HOME", fakeHome)
+
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	destDir := filepath.Join(tmpdir, "dest")
+
+	if err := os.MkdirAll(filepath.Join(sourceDir, "broken"), 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "broken", ".forgignore"), []byte("[oops\n"), 0o600); err != nil {
+		t.Fatalf("creating ignore file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "report.pdf"), []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("creating source file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Source:   sourceDir,
+		Conflict: "skip",
+		Rules: []config.RuleConfig{
+			{
+				Name:        "Documents",
+				Match:       config.MatchConfig{Extensions: []string{".pdf"}},
+				Destination: destDir,
+			},
+		},
+	}
+
+	if _, err := organizer.Run(t.Context(), cfg, organizer.Options{Recursive: true}, noopLogger); err == nil {
+		t.Fatal("expected strict run to fail on the unreadable ignore file")
+	}
+
+	report, err := organizer.Run(t.Context(), cfg, organizer.Options{Recursive: true, Tolerant: true}, noopLogger)
+	if err != nil {
+		t.Fatalf("Run(Tolerant): %v", err)
+	}
+	if report.Moved != 1 {
+		t.Errorf("expected 1 moved, got %d", report.Moved)
+	}
+	if len(report.ScanErrors) != 1 {
+		t.Errorf("expected 1 scan error, got %v", report.ScanErrors)
+	}
+}
+
+func TestIntegration_CacheKeptOnScanError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "z-broken"), 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	// a.pdf sorts before the broken directory, so it is decided before the
+	// scan fails.
+	report := filepath.Join(sourceDir, "a.pdf")
+	if err := os.WriteFile(report, []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("creating source file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "z-broken", ".forgignore"), []byte("[oops\n"), 0o600); err != nil {
+		t.Fatalf("creating ignore file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Source: sourceDir,
+		Rules: []config.RuleConfig{
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: filepath.Join(tmpdir, "docs")},
+		},
+	}
+	opts := organizer.Options{DryRun: true, Recursive: true, Cache: true}
+	if _, err := organizer.Run(t.Context(), cfg, opts, noopLogger); err == nil {
+		t.Fatal("expected strict run to fail on the unreadable ignore file")
+	}
+
+	path, err := cache.Path(sourceDir)
+	if err != nil {
+		t.Fatalf("cache.Path: %v", err)
+	}
+	hash, err := cfg.Hash()
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	idx, err := cache.Load(path, hash)
+	if err != nil {
+		t.Fatalf("cache.Load: %v", err)
+	}
+	if _, ok := idx.Entries[report]; !ok {
+		t.Errorf("expected the failed run to cache its decision for %s, got %v", report, idx.Entries)
+	}
+}
+
+func TestIntegration_CacheKeptForUnscannedPaths(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	nested := filepath.Join(sourceDir, "sub", "b.pdf")
+	if err := os.MkdirAll(filepath.Dir(nested), 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	if err := os.WriteFile(nested, []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("creating source file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Source: sourceDir,
+		Rules: []config.RuleConfig{
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: filepath.Join(tmpdir, "docs")},
+		},
+	}
+	opts := organizer.Options{DryRun: true, Recursive: true, Tolerant: true, Cache: true}
+	if _, err := organizer.Run(t.Context(), cfg, opts, noopLogger); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	// sub can no longer be scanned, so the tolerant run never sees b.pdf.
+	if err := os.WriteFile(filepath.Join(sourceDir, "sub", ".forgignore"), []byte("[oops\n"), 0o600); err != nil {
+		t.Fatalf("creating ignore file: %v", err)
+	}
+	report, err := organizer.Run(t.Context(), cfg, opts, noopLogger)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(report.ScanErrors) != 1 {
+		t.Fatalf("expected one scan error, got %v", report.ScanErrors)
+	}
+
+	path, err := cache.Path(sourceDir)
+	if err != nil {
+		t.Fatalf("cache.Path: %v", err)
+	}
+	hash, err := cfg.Hash()
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	idx, err := cache.Load(path, hash)
+	if err != nil {
+		t.Fatalf("cache.Load: %v", err)
+	}
+	if _, ok := idx.Entries[nested]; !ok {
+		t.Errorf("expected the cache to keep %s from the unscanned directory, got %v", nested, idx.Entries)
+	}
+}
+
+func TestIntegration_Cancelled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	destDir := filepath.Join(tmpdir, "docs")
+	if err := os.MkdirAll(sourceDir, 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "report.pdf"), []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("creating source file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Source:   sourceDir,
+		Conflict: "skip",
+		Rules: []config.RuleConfig{
+			{
+				Name:        "Documents",
+				Match:       config.MatchConfig{Extensions: []string{".pdf"}},
+				Destination: destDir,
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	report, err := organizer.Run(ctx, cfg, organizer.Options{}, noopLogger)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if report == nil || !report.Interrupted {
+		t.Fatalf("expected an interrupted report, got %+v", report)
+	}
+	if !fileExists(filepath.Join(sourceDir, "report.pdf")) {
+		t.Error("expected no files to be moved after cancellation")
+	}
+}
+
+func TestIntegration_Progress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	destDir := filepath.Join(tmpdir, "docs")
+	if err := os.MkdirAll(sourceDir, 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	for name, content := range map[string]string{"a.pdf": "aaaa", "b.pdf": "bb", "c.txt": "c"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Source:   sourceDir,
+		Conflict: "skip",
+		Rules: []config.RuleConfig{
+			{
+				Name:        "Documents",
+				Match:       config.MatchConfig{Extensions: []string{".pdf"}},
+				Destination: destDir,
+			},
+		},
+	}
+
+	tracker := progress.NewTracker()
+	if _, err := organizer.Run(t.Context(), cfg, organizer.Options{Progress: tracker.Observe}, noopLogger); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got := tracker.Snapshot()
+	if got.Scanned != 3 || got.Matched != 2 || got.Moved != 2 || !got.ScanDone {
+		t.Errorf("unexpected totals %+v", got)
+	}
+	if got.Bytes != 6 || got.TotalBytes != 6 {
+		t.Errorf("expected 6 bytes moved of 6, got %d of %d", got.Bytes, got.TotalBytes)
+	}
+}
+
+func TestIntegration_ProgressSources(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tmpdir := t.TempDir()
+	first := filepath.Join(tmpdir, "first")
+	second := filepath.Join(tmpdir, "second")
+	for _, path := range []string{
+		filepath.Join(first, "a.pdf"),
+		filepath.Join(second, "b.pdf"),
+		filepath.Join(second, "c.pdf"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Sources:  []config.SourceConfig{{Path: first}, {Path: second}},
+		Conflict: "skip",
+		Rules: []config.RuleConfig{
+			{
+				Name:        "Documents",
+				Match:       config.MatchConfig{Extensions: []string{".pdf"}},
+				Destination: filepath.Join(tmpdir, "docs"),
+			},
+		},
+	}
+
+	// checkEvents wants exactly one ScanDone, after every Scanned event.
+	checkEvents := func(t *testing.T, events []progress.Kind) {
+		t.Helper()
+		scanned, done := 0, 0
+		for _, kind := range events {
+			switch kind {
+			case progress.Scanned:
+				if done > 0 {
+					t.Errorf("Scanned event after ScanDone in %v", events)
+				}
+				scanned++
+			case progress.ScanDone:
+				done++
+			}
+		}
+		if scanned != 3 || done != 1 {
+			t.Errorf("got %d Scanned and %d ScanDone events, want 3 and 1", scanned, done)
+		}
+	}
+
+	t.Run("run", func(t *testing.T) {
+		var events []progress.Kind
+		opts := organizer.Options{DryRun: true, Progress: func(e progress.Event) { events = append(events, e.Kind) }}
+		if _, err := organizer.Run(t.Context(), cfg, opts, noopLogger); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		checkEvents(t, events)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		var events []progress.Kind
+		opts := organizer.Options{Progress: func(e progress.Event) { events = append(events, e.Kind) }}
+		if _, err := organizer.Audit(t.Context(), cfg, opts); err != nil {
+			t.Fatalf("Audit: %v", err)
+		}
+		checkEvents(t, events)
+	})
+}
+
+func TestExplain(t *testing.T) {
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	imagesDir := filepath.Join(tmpdir, "images")
+	docsDir := filepath.Join(tmpdir, "docs")
+	for _, dir := range []string{filepath.Join(sourceDir, "nested"), imagesDir} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(sourceDir, "photo.jpg"),
+		filepath.Join(sourceDir, "report.pdf"),
+		filepath.Join(sourceDir, "notes.txt"),
+		filepath.Join(sourceDir, "nested", "deep.pdf"),
+		filepath.Join(imagesDir, "photo.jpg"),
+	} {
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatalf("creating file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Source:   sourceDir,
+		Conflict: "rename",
+		Rules: []config.RuleConfig{
+			{Name: "Images", Match: config.MatchConfig{Extensions: []string{".jpg"}}, Destination: imagesDir},
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: docsDir},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		file        string
+		wantRule    string
+		wantFinal   string
+		wantAction  string
+		wantSkipped string
+		wantTraces  []bool
+	}{
+		{
+			name:       "conflict is renamed",
+			file:       "photo.jpg",
+			wantRule:   "Images",
+			wantFinal:  filepath.Join(imagesDir, "photo-1.jpg"),
+			wantAction: internal.ActionRename,
+			wantTraces: []bool{true, false},
+		},
+		{
+			name:       "second rule matches",
+			file:       "report.pdf",
+			wantRule:   "Documents",
+			wantFinal:  filepath.Join(docsDir, "report.pdf"),
+			wantAction: internal.ActionMove,
+			wantTraces: []bool{false, true},
+		},
+		{
+			name:       "no rule matches",
+			file:       "notes.txt",
+			wantTraces: []bool{false, false},
+		},
+		{
+			name:        "not reached by a flat scan",
+			file:        filepath.Join("nested", "deep.pdf"),
+			wantRule:    "Documents",
+			wantFinal:   filepath.Join(docsDir, "deep.pdf"),
+			wantAction:  internal.ActionMove,
+			wantSkipped: "not recursive",
+			wantTraces:  []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex, err := organizer.Explain(cfg, organizer.Options{}, filepath.Join(sourceDir, tt.file))
+			if err != nil {
+				t.Fatalf("Explain: %v", err)
+			}
+
+			if len(ex.Traces) != len(tt.wantTraces) {
+				t.Fatalf("expected %d traces, got %d", len(tt.wantTraces), len(ex.Traces))
+			}
+			for i, want := range tt.wantTraces {
+				if ex.Traces[i].Matched != want {
+					t.Errorf("trace %d: Matched = %v, want %v", i, ex.Traces[i].Matched, want)
+				}
+			}
+
+			gotRule := ""
+			if ex.Rule != nil {
+				gotRule = ex.Rule.Name
+			}
+			if gotRule != tt.wantRule || ex.Final != tt.wantFinal || ex.Action != tt.wantAction {
+				t.Errorf("got rule %q, final %q, action %q; want %q, %q, %q",
+					gotRule, ex.Final, ex.Action, tt.wantRule, tt.wantFinal, tt.wantAction)
+			}
+			if !strings.Contains(ex.Skipped, tt.wantSkipped) || (tt.wantSkipped == "") != (ex.Skipped == "") {
+				t.Errorf("Skipped = %q, want %q", ex.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	tmpdir := t.TempDir()
+	sourceDir := filepath.Join(tmpdir, "source")
+	if err := os.MkdirAll(sourceDir, 0o750); err != nil {
+		t.Fatalf("creating source dir: %v", err)
+	}
+	for name, content := range map[string]string{
+		"a.jpg":    "aaaa",
+		"b.pdf":    "bb",
+		"c.txt":    "ccc",
+		"d.TXT":    "d",
+		"e.txt":    "",
+		"Makefile": "mm",
+		"f.zip":    "zzzzz",
+	} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Source: sourceDir,
+		Rules: []config.RuleConfig{
+			{Name: "Images", Match: config.MatchConfig{Extensions: []string{".jpg"}}, Destination: filepath.Join(tmpdir, "images")},
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf", ".doc"}}, Destination: filepath.Join(tmpdir, "docs")},
+			{Name: "Word", Match: config.MatchConfig{Extensions: []string{".doc"}}, Destination: filepath.Join(tmpdir, "word")},
+		},
+	}
+
+	cov, err := organizer.Audit(t.Context(), cfg, organizer.Options{})
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+
+	if cov.Scanned != 7 || cov.Matched != 2 || cov.UnmatchedFiles() != 5 {
+		t.Errorf("scanned %d, matched %d, unmatched %d; want 7, 2, 5", cov.Scanned, cov.Matched, cov.UnmatchedFiles())
+	}
+
+	wantGroups := []organizer.ExtensionGroup{
+		{Extension: ".txt", Files: 3, Bytes: 4},
+		{Extension: "", Files: 1, Bytes: 2},
+		{Extension: ".zip", Files: 1, Bytes: 5},
+	}
+	if len(cov.Unmatched) != len(wantGroups) {
+		t.Fatalf("expected %d unmatched groups, got %+v", len(wantGroups), cov.Unmatched)
+	}
+	for i, want := range wantGroups {
+		got := cov.Unmatched[i]
+		if got.Extension != want.Extension || got.Files != want.Files || got.Bytes != want.Bytes {
+			t.Errorf("group %d = %+v, want %+v", i, got, want)
+		}
+		if len(got.Examples) != min(got.Files, 3) {
+			t.Errorf("group %d has %d examples", i, len(got.Examples))
+		}
+	}
+
+	wantRules := []organizer.RuleCoverage{
+		{Name: "Images", Files: 1, Bytes: 4},
+		{Name: "Documents", Files: 1, Bytes: 2},
+		{Name: "Word", Files: 0, Bytes: 0},
+	}
+	for i, want := range wantRules {
+		if cov.Rules[i] != want {
+			t.Errorf("rule %d = %+v, want %+v", i, cov.Rules[i], want)
+		}
+	}
+	if dead := cov.DeadRules(); len(dead) != 1 || dead[0] != "Word" {
+		t.Errorf("DeadRules() = %v, want [Word]", dead)
+	}
+
+	if !fileExists(filepath.Join(sourceDir, "a.jpg")) {
+		t.Error("Audit moved a file")
+	}
+}
+
+func TestAudit_SourceRules(t *testing.T) {
+	tmpdir := t.TempDir()
+	first := filepath.Join(tmpdir, "first")
+	second := filepath.Join(tmpdir, "second")
+	for _, path := range []string{
+		filepath.Join(first, "a.jpg"),
+		filepath.Join(first, "b.pdf"),
+		filepath.Join(second, "c.pdf"),
+		filepath.Join(second, "d.pdf"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatalf("creating source file: %v", err)
+		}
+	}
+
+	// Both sources define their own "Inbox" rule; only the top-level rule
+	// is shared.
+	cfg := &config.Config{
+		Sources: []config.SourceConfig{
+			{
+				Path:  first,
+				Rules: []config.RuleConfig{{Name: "Inbox", Match: config.MatchConfig{Extensions: []string{".jpg"}}, Destination: filepath.Join(tmpdir, "images")}},
+				Use:   []string{"Documents"},
+			},
+			{
+				Path:  second,
+				Rules: []config.RuleConfig{{Name: "Inbox", Match: config.MatchConfig{Extensions: []string{".zip"}}, Destination: filepath.Join(tmpdir, "zips")}},
+				Use:   []string{"Documents"},
+			},
+		},
+		Rules: []config.RuleConfig{
+			{Name: "Documents", Match: config.MatchConfig{Extensions: []string{".pdf"}}, Destination: filepath.Join(tmpdir, "docs")},
+		},
+	}
+
+	cov, err := organizer.Audit(t.Context(), cfg, organizer.Options{})
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+
+	wantRules := []organizer.RuleCoverage{
+		{Name: "Inbox", Source: first, Files: 1, Bytes: 4},
+		{Name: "Documents", Files: 3, Bytes: 12},
+		{Name: "Inbox", Source: second, Files: 0, Bytes: 0},
+	}
+	if len(cov.Rules) != len(wantRules) {
+		t.Fatalf("Rules = %+v, want %+v", cov.Rules, wantRules)
+	}
+	for i, want := range wantRules {
+		if cov.Rules[i] != want {
+			t.Errorf("rule %d = %+v, want %+v", i, cov.Rules[i], want)
+		}
+	}
+	if dead := cov.DeadRules(); len(dead) != 1 || dead[0] != "Inbox ("+second+")" {
+		t.Errorf("DeadRules() = %v, want the second source's Inbox", dead)
+	}
+}
@@ -1,11 +1,15 @@
 package organizer
 
 import (
+	"context"
 	"fmt"
+	"iter"
+	"slices"
 	"time"
 
+	"github.com/devaloi/forg/internal/cache"
 	"github.com/devaloi/forg/internal/config"
-	"github.com/devaloi/forg/internal/rules"
+	"github.com/devaloi/forg/internal/progress"
 	"github.com/devaloi/forg/internal/scanner"
 )
 
@@ -15,41 +19,97 @@ type Options struct {
 	Verbose       bool
 	Recursive     bool
 	IncludeHidden bool
-	ConfigPath    string
+	// Tolerant keeps scanning past unreadable paths and records them in
+	// Report.ScanErrors instead of failing the run.
+	Tolerant bool
+	// Jobs is the number of moves to run concurrently. Values below 2 run
+	// moves sequentially.
+	Jobs int
+	// ScanWorkers is the number of directories read concurrently during a
+	// recursive scan. Values below 2 walk the tree sequentially.
+	ScanWorkers int
+	// SortScan processes files in path order. It buffers the whole scan,
+	// so it is meant for previews of parallel scans.
+	SortScan bool
+	// Cache reuses the rule decisions of earlier runs for files that have
+	// not changed since, and records new decisions for the next run.
+	Cache bool
+	// Progress, if set, receives an event as each file is scanned, matched
+	// and moved. It may be called from several goroutines at once.
+	Progress func(progress.Event)
+	// KeepResults stores the outcome of every operation in Report.Results,
+	// and in dry runs the planned operations in Report.Operations. It costs
+	// memory in proportion to the number of files, so it is off unless the
+	// whole list is needed at the end.
+	KeepResults bool
+	// OnResult, if set, receives the outcome of each operation in plan
+	// order as soon as it is known.
+	OnResult   func(Result)
+	ConfigPath string
 }
 
-// Run executes the full organise workflow: scan the source directory, build a
-// plan from the configured rules, execute the plan, and optionally write an
-// undo log.
-func Run(cfg *config.Config, opts Options, logger func(string, ...interface{})) (*Report, error) {
+// Run executes the full organise workflow: scan each source directory, build a
+// plan from the rules that apply to it, execute the plan, and optionally write an
+// undo log. If scanning fails part-way, the moves already made are still
+// recorded in the undo log and the partial report is returned with the error.
+// Cancelling ctx stops the run after the operations in flight; the moves made
+// so far are likewise recorded and the report has Interrupted set.
+func Run(ctx context.Context, cfg *config.Config, opts Options, logger func(string, ...interface{})) (*Report, error) {
 	if logger == nil {
 		logger = func(string, ...interface{}) {}
 	}
 
-	engine, err := rules.NewEngine(cfg.Rules)
+	sources, err := prepareSources(cfg, opts)
 	if err != nil {
-		return nil, fmt.Errorf("building rule engine: %w", err)
+		return nil, err
 	}
 
-	sc := scanner.New(scanner.Options{
-		Recursive:     opts.Recursive,
-		IncludeHidden: opts.IncludeHidden,
-	})
-
-	source, err := config.ExpandPath(cfg.Source)
-	if err != nil {
-		return nil, fmt.Errorf("expanding source path: %w", err)
+	// Scanning, matching and execution run as one streamed pipeline so
+	// moves start while the walk continues and memory stays bounded. The
+	// sources are scanned one after another into a single plan.
+	type cacheFile struct {
+		idx  *cache.Index
+		path string
 	}
-
-	files, err := sc.Scan(source)
-	if err != nil {
-		return nil, fmt.Errorf("scanning source directory: %w", err)
+	var caches []cacheFile
+	var collisions *collisionTracker
+	if len(sources) > 1 {
+		collisions = newCollisionTracker()
 	}
+	plans := make([]iter.Seq2[MoveOp, error], 0, len(sources))
+	for _, s := range sources {
+		var idx *cache.Index
+		if opts.Cache {
+			var path string
+			if idx, path, err = loadCache(cfg, s.dir); err != nil {
+				return nil, err
+			}
+			caches = append(caches, cacheFile{idx, path})
+		}
 
-	plan := BuildPlan(files, engine)
+		files := s.scanner.Files(ctx, s.dir)
+		if opts.Progress != nil {
+			files = observeScan(files, opts.Progress)
+		}
+		plan := PlanCached(files, s.engine, idx)
+		if collisions != nil {
+			plan = collisions.track(plan, s.dir)
+		}
+		plans = append(plans, plan)
+	}
+	plan := chain(plans)
+	if opts.Progress != nil {
+		plan = observePlan(observeScanDone(plan, opts.Progress), opts.Progress)
+	}
 
 	executor := NewExecutor(cfg.Conflict, opts.Verbose, logger)
-	report, undoEntries := executor.Execute(plan, opts.DryRun)
+	executor.SetJobs(opts.Jobs)
+	executor.SetProgress(opts.Progress)
+	executor.SetResults(opts.KeepResults, opts.OnResult)
+	report, undoEntries, execErr := executor.ExecuteStream(ctx, plan, opts.DryRun)
+	if collisions != nil {
+		report.Collisions = collisions.collisions()
+	}
 
 	if !opts.DryRun && len(undoEntries) > 0 {
 		undoLog := &UndoLog{
@@ -62,5 +122,65 @@ func Run(cfg *config.Config, opts Options, logger func(string, ...interface{}))
 		}
 	}
 
+	// A run that stopped early still keeps the decisions it made, so the
+	// next run starts where this one left off. Its own error takes
+	// precedence over a failure to write the cache. Entries under paths
+	// that could not be scanned were not seen either, so they are kept
+	// too.
+	stopped := report.Interrupted || execErr != nil
+	for _, c := range caches {
+		save := c.idx.Save
+		if stopped || len(report.ScanErrors) > 0 {
+			save = c.idx.SavePartial
+		}
+		if err := save(c.path); err != nil && !stopped {
+			return report, fmt.Errorf("writing scan cache: %w", err)
+		}
+	}
+
+	if report.Interrupted {
+		return report, fmt.Errorf("interrupted: %w", execErr)
+	}
+	if execErr != nil {
+		return report, fmt.Errorf("scanning source directory: %w", execErr)
+	}
+
 	return report, nil
 }
+
+// newScanner returns the scanner Run uses for src of cfg with opts, never
+// descending into skipDirs.
+func newScanner(cfg *config.Config, src config.SourceConfig, opts Options, skipDirs []string) *scanner.Scanner {
+	return scanner.New(scanner.Options{
+		Recursive:     opts.Recursive || src.Recursive,
+		IncludeHidden: opts.IncludeHidden || src.IncludeHidden,
+		Exclude:       append(slices.Clone(cfg.Exclude), src.Exclude...),
+		SkipDirs:      skipDirs,
+		Opaque:        cfg.Opaque,
+		MinDepth:      cfg.MinDepth,
+		MaxDepth:      cfg.MaxDepth,
+		Symlinks:      cfg.Symlinks,
+		Types:         cfg.SpecialTypes(),
+		Tolerant:      opts.Tolerant,
+		Workers:       opts.ScanWorkers,
+		Sorted:        opts.SortScan,
+	})
+}
+
+// loadCache opens the scan cache for source, discarding it if it was written
+// for a different configuration.
+func loadCache(cfg *config.Config, source string) (*cache.Index, string, error) {
+	hash, err := cfg.Hash()
+	if err != nil {
+		return nil, "", fmt.Errorf("hashing config: %w", err)
+	}
+	path, err := cache.Path(source)
+	if err != nil {
+		return nil, "", fmt.Errorf("determining scan cache path: %w", err)
+	}
+	idx, err := cache.Load(path, hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading scan cache: %w", err)
+	}
+	return idx, path, nil
+}
@@ -1,13 +1,21 @@
 package organizer
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/cache"
 	"github.com/devaloi/forg/internal/config"
 	"github.com/devaloi/forg/internal/rules"
 	"github.com/devaloi/forg/internal/scanner"
@@ -99,6 +107,351 @@ func TestBuildPlan_NoMatches(t *testing.T) {
 	}
 }
 
+func TestPlan_Streaming(t *testing.T) {
+	engine, err := rules.NewEngine([]config.RuleConfig{
+		{
+			Name:        "images",
+			Match:       config.MatchConfig{Extensions: []string{".png"}},
+			Destination: "/images",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	scanErr := scanner.ScanError{Path: "/src/locked", Err: errors.New("permission denied")}
+	files := func(yield func(scanner.FileInfo, error) bool) {
+		_ = yield(scanner.FileInfo{Path: "/src/a.png", Name: "a.png", Extension: ".png"}, nil) &&
+			yield(scanner.FileInfo{Path: "/src/b.txt", Name: "b.txt", Extension: ".txt"}, nil) &&
+			yield(scanner.FileInfo{}, scanErr) &&
+			yield(scanner.FileInfo{Path: "/src/c.png", Name: "c.png", Extension: ".png"}, nil)
+	}
+
+	var ops []MoveOp
+	var errs []error
+	for op, err := range Plan(files, engine) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	if len(ops) != 2 || ops[0].Source != "/src/a.png" || ops[1].Source != "/src/c.png" {
+		t.Errorf("unexpected ops %v", ops)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected the scan error to be passed through, got %v", errs)
+	}
+}
+
+func TestPlanCached(t *testing.T) {
+	engine, err := rules.NewEngine([]config.RuleConfig{
+		{
+			Name:        "images",
+			Match:       config.MatchConfig{Extensions: []string{".png"}},
+			Destination: "/images",
+		},
+		{
+			Name:        "docs",
+			Match:       config.MatchConfig{Extensions: []string{".txt"}},
+			Destination: "/docs",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	idx, err := cache.Load(cachePath, "hash")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	mtime := time.Now().Add(-time.Hour)
+	cached := scanner.FileInfo{Path: "/src/a.png", Name: "a.png", Extension: ".png", Size: 1, ModTime: mtime}
+	fresh := scanner.FileInfo{Path: "/src/b.txt", Name: "b.txt", Extension: ".txt", Size: 1, ModTime: mtime}
+
+	// A stale decision proves the cached entry is used instead of the rules.
+	idx.Entries[cached.Path] = cache.Entry{Size: cached.Size, ModTime: cached.ModTime, Rule: 1}
+
+	files := func(yield func(scanner.FileInfo, error) bool) {
+		_ = yield(cached, nil) && yield(fresh, nil)
+	}
+
+	var ops []MoveOp
+	for op, err := range PlanCached(files, engine, idx) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ops = append(ops, op)
+	}
+
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 ops, got %v", ops)
+	}
+	if ops[0].RuleName != "docs" {
+		t.Errorf("cached file: RuleName = %q, want the cached %q", ops[0].RuleName, "docs")
+	}
+	if ops[1].RuleName != "docs" {
+		t.Errorf("new file: RuleName = %q, want %q", ops[1].RuleName, "docs")
+	}
+
+	if err := idx.Save(cachePath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if idx, err = cache.Load(cachePath, "hash"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rule, ok := idx.Lookup(fresh, time.Now()); !ok || rule != 1 {
+		t.Errorf("expected the new decision to be recorded, got %d, %v", rule, ok)
+	}
+}
+
+func TestExecuteStream(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	src1 := createTempFile(t, srcDir, "a.txt", "aaa")
+	src2 := createTempFile(t, srcDir, "b.txt", "bbb")
+	fatal := errors.New("walk failed")
+
+	ops := func(items []MoveOp, errs []error) iter.Seq2[MoveOp, error] {
+		return func(yield func(MoveOp, error) bool) {
+			for i := range items {
+				if !yield(items[i], errs[i]) {
+					return
+				}
+			}
+		}
+	}
+
+	exec := NewExecutor("skip", false, nil)
+	report, undoEntries, err := exec.ExecuteStream(t.Context(), ops(
+		[]MoveOp{
+			{Source: src1, Destination: destDir, RuleName: "r"},
+			{},
+			{},
+			{Source: src2, Destination: destDir, RuleName: "r"},
+		},
+		[]error{nil, scanner.ScanError{Path: "/x", Err: errors.New("denied")}, fatal, nil},
+	), false)
+
+	if !errors.Is(err, fatal) {
+		t.Fatalf("expected fatal error, got %v", err)
+	}
+	if report.Moved != 1 || len(undoEntries) != 1 {
+		t.Errorf("expected the move before the error to be kept, Moved=%d undo=%d", report.Moved, len(undoEntries))
+	}
+	if len(report.ScanErrors) != 1 {
+		t.Errorf("expected 1 scan error, got %v", report.ScanErrors)
+	}
+	if !fileExists(t, src2) {
+		t.Error("operations after a fatal error should not run")
+	}
+}
+
+func TestExecuteStream_Parallel(t *testing.T) {
+	srcRoot := t.TempDir()
+	destDir := t.TempDir()
+
+	// Every source file has the same name, so concurrent workers all
+	// contend for the same destination name.
+	const n = 40
+	plan := make([]MoveOp, 0, n)
+	for i := range n {
+		dir := filepath.Join(srcRoot, fmt.Sprintf("d%02d", i))
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		src := createTempFile(t, dir, "same.txt", fmt.Sprintf("content %d", i))
+		plan = append(plan, MoveOp{Source: src, Destination: destDir, RuleName: "r"})
+	}
+
+	ops := func(yield func(MoveOp, error) bool) {
+		for _, op := range plan {
+			if !yield(op, nil) {
+				return
+			}
+		}
+	}
+
+	exec := NewExecutor(internal.ConflictRename, false, nil)
+	exec.SetJobs(8)
+	report, undoEntries, err := exec.ExecuteStream(t.Context(), ops, false)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+
+	if report.Moved != n || report.Errors != 0 {
+		t.Fatalf("expected %d moved and no errors, got Moved=%d Errors=%d", n, report.Moved, report.Errors)
+	}
+
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		t.Fatalf("reading dest: %v", err)
+	}
+	if len(entries) != n {
+		t.Errorf("expected %d distinct files at destination, got %d", n, len(entries))
+	}
+
+	t.Run("undo entries in plan order", func(t *testing.T) {
+		if len(undoEntries) != n {
+			t.Fatalf("expected %d undo entries, got %d", n, len(undoEntries))
+		}
+		seen := make(map[string]bool)
+		for i, entry := range undoEntries {
+			if entry.From != plan[i].Source {
+				t.Errorf("undoEntries[%d].From = %s, want %s", i, entry.From, plan[i].Source)
+			}
+			if seen[entry.To] {
+				t.Errorf("destination %s used twice", entry.To)
+			}
+			seen[entry.To] = true
+		}
+	})
+
+	t.Run("results in plan order", func(t *testing.T) {
+		if len(report.Results) != n {
+			t.Fatalf("expected %d results, got %d", n, len(report.Results))
+		}
+		for i, r := range report.Results {
+			if r.Source != plan[i].Source {
+				t.Errorf("Results[%d].Source = %s, want %s", i, r.Source, plan[i].Source)
+			}
+		}
+	})
+}
+
+func TestExecuteStream_StreamedResults(t *testing.T) {
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			srcDir := t.TempDir()
+			destDir := t.TempDir()
+
+			var plan []MoveOp
+			for i := range 10 {
+				src := createTempFile(t, srcDir, fmt.Sprintf("f%d.txt", i), "x")
+				plan = append(plan, MoveOp{Source: src, Destination: destDir, RuleName: "r"})
+			}
+			ops := func(yield func(MoveOp, error) bool) {
+				for _, op := range plan {
+					if !yield(op, nil) {
+						return
+					}
+				}
+			}
+
+			// The last move waits for the first result, so results that
+			// were held back until every operation finished would stall it.
+			var (
+				streamed []Result
+				once     sync.Once
+			)
+			first := make(chan struct{})
+			fs := &gateFS{gate: plan[len(plan)-1].Source, open: first}
+			exec := NewExecutorWithFS(fs, internal.ConflictSkip, false, nil)
+			exec.SetJobs(jobs)
+			exec.SetResults(false, func(r Result) {
+				streamed = append(streamed, r)
+				once.Do(func() { close(first) })
+			})
+			report, _, err := exec.ExecuteStream(t.Context(), ops, false)
+			if err != nil {
+				t.Fatalf("ExecuteStream: %v", err)
+			}
+			if fs.timedOut.Load() {
+				t.Error("expected a result before the last operation finished")
+			}
+
+			if len(report.Results) != 0 {
+				t.Errorf("expected no results kept in the report, got %d", len(report.Results))
+			}
+			if report.Total() != len(plan) {
+				t.Errorf("Total() = %d, want %d", report.Total(), len(plan))
+			}
+			if len(streamed) != len(plan) {
+				t.Fatalf("expected %d streamed results, got %d", len(plan), len(streamed))
+			}
+			for i, r := range streamed {
+				if r.Source != plan[i].Source {
+					t.Errorf("streamed[%d].Source = %s, want %s", i, r.Source, plan[i].Source)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteStream_StreamedDryRun(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+	plan := []MoveOp{
+		{Source: createTempFile(t, srcDir, "a.txt", "x"), Destination: destDir, RuleName: "r"},
+		{Source: createTempFile(t, srcDir, "b.txt", "x"), Destination: destDir, RuleName: "r"},
+	}
+	ops := func(yield func(MoveOp, error) bool) {
+		for _, op := range plan {
+			if !yield(op, nil) {
+				return
+			}
+		}
+	}
+
+	streamed := 0
+	exec := NewExecutor(internal.ConflictSkip, false, nil)
+	exec.SetResults(false, func(Result) { streamed++ })
+	report, _, err := exec.ExecuteStream(t.Context(), ops, true)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	if len(report.Operations) != 0 || len(report.Results) != 0 {
+		t.Errorf("expected nothing kept in the report, got %d operations and %d results", len(report.Operations), len(report.Results))
+	}
+	if streamed != len(plan) || report.Moved != len(plan) {
+		t.Errorf("streamed %d results, Moved = %d; want %d", streamed, report.Moved, len(plan))
+	}
+}
+
+func TestExecuteStream_ParallelSkip(t *testing.T) {
+	srcRoot := t.TempDir()
+	destDir := t.TempDir()
+
+	var plan []MoveOp
+	for i := range 10 {
+		dir := filepath.Join(srcRoot, fmt.Sprintf("d%d", i))
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("creating dir: %v", err)
+		}
+		plan = append(plan, MoveOp{Source: createTempFile(t, dir, "dup.txt", "x"), Destination: destDir, RuleName: "r"})
+	}
+
+	ops := func(yield func(MoveOp, error) bool) {
+		for _, op := range plan {
+			if !yield(op, nil) {
+				return
+			}
+		}
+	}
+
+	exec := NewExecutor(internal.ConflictSkip, false, nil)
+	exec.SetJobs(4)
+	report, _, err := exec.ExecuteStream(t.Context(), ops, false)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+
+	if report.Moved != 1 || report.Skipped != 9 || report.Conflicts != 9 {
+		t.Errorf("expected 1 moved and 9 skipped, got Moved=%d Skipped=%d Conflicts=%d",
+			report.Moved, report.Skipped, report.Conflicts)
+	}
+}
+
+func fileExists(t *testing.T, path string) bool {
+	t.Helper()
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 func TestExecute_DryRun(t *testing.T) {
 	srcDir := t.TempDir()
 	destDir := t.TempDir()
@@ -110,7 +463,7 @@ func TestExecute_DryRun(t *testing.T) {
 	}
 
 	exec := NewExecutor("skip", false, nil)
-	report, undoEntries := exec.Execute(plan, true)
+	report, undoEntries := exec.Execute(t.Context(), plan, true)
 
 	t.Run("report flags", func(t *testing.T) {
 		if !report.DryRun {
@@ -156,7 +509,7 @@ func TestExecute_MoveFiles(t *testing.T) {
 	}
 
 	exec := NewExecutor("skip", false, nil)
-	report, undoEntries := exec.Execute(plan, false)
+	report, undoEntries := exec.Execute(t.Context(), plan, false)
 
 	t.Run("report counts", func(t *testing.T) {
 		if report.Moved != 2 {
@@ -209,7 +562,7 @@ func TestExecute_ConflictSkip(t *testing.T) {
 	}
 
 	exec := NewExecutor("skip", false, nil)
-	report, _ := exec.Execute(plan, false)
+	report, _ := exec.Execute(t.Context(), plan, false)
 
 	t.Run("file not moved", func(t *testing.T) {
 		if _, err := os.Stat(srcFile); err != nil {
@@ -242,7 +595,7 @@ func TestExecute_ConflictRename(t *testing.T) {
 	}
 
 	exec := NewExecutor("rename", false, nil)
-	report, undoEntries := exec.Execute(plan, false)
+	report, undoEntries := exec.Execute(t.Context(), plan, false)
 
 	t.Run("report counts", func(t *testing.T) {
 		if report.Moved != 1 {
@@ -291,7 +644,7 @@ func TestExecute_ConflictOverwrite(t *testing.T) {
 	}
 
 	exec := NewExecutor("overwrite", false, nil)
-	report, _ := exec.Execute(plan, false)
+	report, _ := exec.Execute(t.Context(), plan, false)
 
 	t.Run("report counts", func(t *testing.T) {
 		if report.Moved != 1 {
@@ -328,7 +681,7 @@ func TestExecute_CreatesDestDir(t *testing.T) {
 	}
 
 	exec := NewExecutor("skip", false, nil)
-	report, _ := exec.Execute(plan, false)
+	report, _ := exec.Execute(t.Context(), plan, false)
 
 	t.Run("destination dir created", func(t *testing.T) {
 		info, err := os.Stat(destDir)
@@ -351,6 +704,125 @@ func TestExecute_CreatesDestDir(t *testing.T) {
 	})
 }
 
+func TestExecute_RelativeSymlink(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	target := createTempFile(t, srcDir, "target.txt", "linked")
+	link := filepath.Join(srcDir, "link.txt")
+	if err := os.Symlink("target.txt", link); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	plan := []MoveOp{
+		{Source: link, Destination: destDir, RuleName: "links", Symlink: true},
+	}
+
+	exec := NewExecutor("skip", false, nil)
+	report, undoEntries := exec.Execute(t.Context(), plan, false)
+
+	if report.Moved != 1 {
+		t.Fatalf("expected Moved=1, got %d (errors=%d)", report.Moved, report.Errors)
+	}
+
+	moved := filepath.Join(destDir, "link.txt")
+	t.Run("link still resolves", func(t *testing.T) {
+		got, err := os.Readlink(moved)
+		if err != nil {
+			t.Fatalf("reading moved link: %v", err)
+		}
+		if filepath.IsAbs(got) || filepath.Join(destDir, got) != target {
+			t.Errorf("link target = %q, want %s relative to %s", got, target, destDir)
+		}
+		data, err := os.ReadFile(moved) //nolint:gosec // test file path
+		if err != nil || string(data) != "linked" {
+			t.Errorf("reading through moved link = %q, %v", data, err)
+		}
+	})
+
+	t.Run("original link removed", func(t *testing.T) {
+		if _, err := os.Lstat(link); !os.IsNotExist(err) {
+			t.Errorf("expected original link to be gone, err=%v", err)
+		}
+	})
+
+	t.Run("undo entry recorded", func(t *testing.T) {
+		if len(undoEntries) != 1 || undoEntries[0].To != moved || undoEntries[0].Link != "target.txt" {
+			t.Errorf("unexpected undo entries %v", undoEntries)
+		}
+	})
+}
+
+func TestExecute_SymlinkAndTargetMoved(t *testing.T) {
+	for _, linkFirst := range []bool{true, false} {
+		t.Run(fmt.Sprintf("link first=%v", linkFirst), func(t *testing.T) {
+			srcDir := t.TempDir()
+			docsDir := filepath.Join(t.TempDir(), "docs")
+			linksDir := filepath.Join(t.TempDir(), "links")
+
+			target := createTempFile(t, srcDir, "target.txt", "linked")
+			link := filepath.Join(srcDir, "link.txt")
+			if err := os.Symlink("target.txt", link); err != nil {
+				t.Fatalf("creating symlink: %v", err)
+			}
+
+			plan := []MoveOp{
+				{Source: link, Destination: linksDir, RuleName: "links", Symlink: true},
+				{Source: target, Destination: docsDir, RuleName: "docs"},
+			}
+			if !linkFirst {
+				plan[0], plan[1] = plan[1], plan[0]
+			}
+
+			exec := NewExecutor(internal.ConflictSkip, false, nil)
+			report, undoEntries := exec.Execute(t.Context(), plan, false)
+			if report.Moved != 2 {
+				t.Fatalf("expected Moved=2, got %d (failures %v)", report.Moved, report.Failures)
+			}
+
+			movedLink := filepath.Join(linksDir, "link.txt")
+			data, err := os.ReadFile(movedLink) //nolint:gosec // test file path
+			if err != nil || string(data) != "linked" {
+				t.Errorf("reading through moved link = %q, %v", data, err)
+			}
+			if text, _ := os.Readlink(movedLink); filepath.IsAbs(text) {
+				t.Errorf("link target = %q, want it to stay relative", text)
+			}
+
+			if err := ExecuteUndo(&UndoLog{Operations: undoEntries}, false, nil); err != nil {
+				t.Fatalf("ExecuteUndo: %v", err)
+			}
+			if text, err := os.Readlink(link); err != nil || text != "target.txt" {
+				t.Errorf("restored link = %q, %v; want the original text target.txt", text, err)
+			}
+			if data, err := os.ReadFile(link); err != nil || string(data) != "linked" { //nolint:gosec // test file path
+				t.Errorf("reading through restored link = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ConflictWithDanglingLink(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	srcFile := createTempFile(t, srcDir, "file.txt", "content")
+	if err := os.Symlink("nowhere", filepath.Join(destDir, "file.txt")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	plan := []MoveOp{
+		{Source: srcFile, Destination: destDir, RuleName: "skip-rule"},
+	}
+
+	exec := NewExecutor("skip", false, nil)
+	report, _ := exec.Execute(t.Context(), plan, false)
+
+	if report.Skipped != 1 {
+		t.Errorf("expected dangling link at destination to count as a conflict, Skipped=%d", report.Skipped)
+	}
+}
+
 func TestExecuteUndo(t *testing.T) {
 	dirA := t.TempDir()
 	dirB := t.TempDir()
@@ -475,7 +947,7 @@ func TestFindUniqueName_Exhaustion(t *testing.T) {
 		{Source: "/tmp/src.txt", Destination: destDir, RuleName: "exhaust-rule"},
 	}
 
-	report, _ := exec.Execute(plan, false)
+	report, _ := exec.Execute(t.Context(), plan, false)
 
 	if report.Errors != 1 {
 		t.Errorf("expected 1 error from exhaustion, got %d", report.Errors)
@@ -495,3 +967,180 @@ func TestFindUniqueName_Exhaustion(t *testing.T) {
 		t.Errorf("expected error containing %q, got %q", wantSubstr, got)
 	}
 }
+
+// gateFS is a FileSystem whose rename of gate waits until open is closed,
+// giving up after a few seconds.
+type gateFS struct {
+	OSFileSystem
+	gate     string
+	open     <-chan struct{}
+	timedOut atomic.Bool
+}
+
+func (fs *gateFS) Rename(oldpath, newpath string) error {
+	if oldpath == fs.gate {
+		select {
+		case <-fs.open:
+		case <-time.After(5 * time.Second):
+			fs.timedOut.Store(true)
+		}
+	}
+	return fs.OSFileSystem.Rename(oldpath, newpath)
+}
+
+// cancelFS is a FileSystem that cancels a context after its first rename,
+// as if the user pressed Ctrl-C while that move was in flight.
+type cancelFS struct {
+	OSFileSystem
+	cancel context.CancelFunc
+}
+
+func (fs cancelFS) Rename(oldpath, newpath string) error {
+	defer fs.cancel()
+	return fs.OSFileSystem.Rename(oldpath, newpath)
+}
+
+func TestExecute_Cancelled(t *testing.T) {
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			srcDir := t.TempDir()
+			destDir := t.TempDir()
+
+			var plan []MoveOp
+			for i := range 10 {
+				src := createTempFile(t, srcDir, fmt.Sprintf("f%d.txt", i), "x")
+				plan = append(plan, MoveOp{Source: src, Destination: destDir, RuleName: "r"})
+			}
+			ops := func(yield func(MoveOp, error) bool) {
+				for _, op := range plan {
+					if !yield(op, nil) {
+						return
+					}
+				}
+			}
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			exec := NewExecutorWithFS(cancelFS{cancel: cancel}, internal.ConflictSkip, false, nil)
+			exec.SetJobs(jobs)
+
+			report, undoEntries, err := exec.ExecuteStream(ctx, ops, false)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if !report.Interrupted {
+				t.Error("expected report to be marked interrupted")
+			}
+			if report.Moved == 0 || report.Moved >= len(plan) {
+				t.Errorf("expected the in-flight moves only, got Moved=%d", report.Moved)
+			}
+			if len(undoEntries) != report.Moved {
+				t.Errorf("expected an undo entry per completed move, got %d for %d moves", len(undoEntries), report.Moved)
+			}
+		})
+	}
+}
+
+func TestExecute_CancelledSlice(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	exec := NewExecutor(internal.ConflictSkip, false, nil)
+	report, undoEntries := exec.Execute(ctx, []MoveOp{{Source: "/src/a.txt", Destination: "/dest", RuleName: "r"}}, false)
+	if !report.Interrupted || report.Moved != 0 || len(undoEntries) != 0 {
+		t.Errorf("expected nothing to run after cancellation, got %+v", report)
+	}
+}
+
+func TestExecute_Results(t *testing.T) {
+	tests := []struct {
+		name       string
+		conflict   string
+		existing   bool
+		wantAction string
+		wantStatus string
+		wantName   string
+	}{
+		{name: "moved", conflict: internal.ConflictSkip, wantAction: "move", wantStatus: "moved", wantName: "a.txt"},
+		{name: "renamed", conflict: internal.ConflictRename, existing: true, wantAction: "rename", wantStatus: "conflict", wantName: "a-1.txt"},
+		{name: "overwritten", conflict: internal.ConflictOverwrite, existing: true, wantAction: "overwrite", wantStatus: "conflict", wantName: "a.txt"},
+		{name: "skipped", conflict: internal.ConflictSkip, existing: true, wantAction: "skip", wantStatus: "skipped", wantName: "a.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			destDir := t.TempDir()
+			src := createTempFile(t, srcDir, "a.txt", "new")
+			if tt.existing {
+				createTempFile(t, destDir, "a.txt", "old")
+			}
+
+			exec := NewExecutor(tt.conflict, false, nil)
+			report, _ := exec.Execute(t.Context(), []MoveOp{{Source: src, Destination: destDir, RuleName: "r"}}, false)
+
+			if len(report.Results) != 1 {
+				t.Fatalf("expected 1 result, got %v", report.Results)
+			}
+			want := Result{
+				Source:      src,
+				Destination: filepath.Join(destDir, tt.wantName),
+				Rule:        "r",
+				Action:      tt.wantAction,
+				Status:      tt.wantStatus,
+			}
+			if got := report.Results[0]; got != want {
+				t.Errorf("Results[0] = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		srcDir := t.TempDir()
+		src := createTempFile(t, srcDir, "a.txt", "x")
+		blocker := createTempFile(t, t.TempDir(), "file", "x")
+
+		exec := NewExecutor(internal.ConflictSkip, false, nil)
+		report, _ := exec.Execute(t.Context(), []MoveOp{{Source: src, Destination: filepath.Join(blocker, "sub"), RuleName: "r"}}, false)
+
+		if len(report.Results) != 1 {
+			t.Fatalf("expected 1 result, got %v", report.Results)
+		}
+		if got := report.Results[0]; got.Status != "error" || got.Error == "" {
+			t.Errorf("expected an error result with a message, got %+v", got)
+		}
+		if len(report.Failures) != 1 {
+			t.Fatalf("expected 1 failure, got %v", report.Failures)
+		}
+		var pathErr *os.PathError
+		if f := report.Failures[0]; f.Op.Source != src || !errors.As(f, &pathErr) {
+			t.Errorf("expected a failure for %s wrapping the mkdir error, got %v", src, f)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		exec := NewExecutor(internal.ConflictSkip, false, nil)
+		report, _ := exec.Execute(t.Context(), []MoveOp{{Source: "/src/a.txt", Destination: "/dest", RuleName: "r"}}, true)
+		if len(report.Results) != 1 || report.Results[0].Status != "planned" || report.Results[0].Destination != "/dest/a.txt" {
+			t.Errorf("unexpected dry-run results %+v", report.Results)
+		}
+	})
+}
+
+func TestCollisionTracker(t *testing.T) {
+	tr := newCollisionTracker()
+	tr.add("/out/a.txt", "/one", "/one/a.txt")
+	tr.add("/out/b.txt", "/one", "/one/b.txt")
+	tr.add("/out/b.txt", "/one", "/one/sub/b.txt")
+	tr.add("/out/a.txt", "/two", "/two/a.txt")
+	tr.add("/out/a.txt", "/one", "/one/x/a.txt")
+
+	got := tr.collisions()
+	if len(got) != 1 {
+		t.Fatalf("collisions() = %+v, want only /out/a.txt", got)
+	}
+	want := []string{"/one/a.txt", "/two/a.txt", "/one/x/a.txt"}
+	if got[0].Destination != "/out/a.txt" || !slices.Equal(got[0].Files, want) {
+		t.Errorf("collision = %+v, want /out/a.txt from %v", got[0], want)
+	}
+}
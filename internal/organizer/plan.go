@@ -2,6 +2,11 @@
 package organizer
 
 import (
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/devaloi/forg/internal/cache"
 	"github.com/devaloi/forg/internal/rules"
 	"github.com/devaloi/forg/internal/scanner"
 )
@@ -12,16 +17,82 @@ type MoveOp struct {
 	Source      string
 	Destination string
 	RuleName    string
+	// Symlink is true when Source is a symbolic link that is moved as-is.
+	Symlink bool
+	// Size is the size of Source in bytes, as scanned.
+	Size int64
+}
+
+// Result records what happened to a single operation.
+type Result struct {
+	Source string `json:"source"`
+	// Destination is the final path of the file, or the path it would
+	// have been moved to when it was skipped, failed or only planned.
+	Destination string `json:"destination"`
+	Rule        string `json:"rule"`
+	// Action is one of the internal.Action* values.
+	Action string `json:"action"`
+	// Status is one of the internal.Status* values.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
+// OpError describes an operation that failed.
+type OpError struct {
+	Op MoveOp
+	// Destination is the path the file was being moved to.
+	Destination string
+	Err         error
+}
+
+// Error describes the failed move and its cause.
+func (e OpError) Error() string {
+	return fmt.Sprintf("moving %s to %s: %v", e.Op.Source, e.Destination, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e OpError) Unwrap() error { return e.Err }
+
 // Report summarises the results of executing a plan.
 type Report struct {
-	Moved      int
-	Skipped    int
-	Conflicts  int
-	Errors     int
-	DryRun     bool
+	Moved     int
+	Skipped   int
+	Conflicts int
+	Errors    int
+	DryRun    bool
+	// Operations lists the planned operations of a dry run, unless the
+	// executor was told not to keep results.
 	Operations []MoveOp
+	// Results lists the outcome of every operation in plan order, unless
+	// the executor was told not to keep them.
+	Results []Result
+	// Failures lists the operations counted in Errors, in plan order.
+	Failures []OpError
+	// ScanErrors lists paths that could not be scanned in tolerant mode.
+	ScanErrors []scanner.ScanError
+	// Interrupted is true when the run was cancelled before every file had
+	// been processed.
+	Interrupted bool
+	// Collisions lists destination paths planned from more than one source.
+	Collisions []Collision
+}
+
+// Total returns the number of operations processed.
+func (r *Report) Total() int {
+	return r.Moved + r.Skipped + r.Errors
+}
+
+// merge adds the counters and entries of o to r.
+func (r *Report) merge(o *Report) {
+	r.Moved += o.Moved
+	r.Skipped += o.Skipped
+	r.Conflicts += o.Conflicts
+	r.Errors += o.Errors
+	r.Operations = append(r.Operations, o.Operations...)
+	r.Results = append(r.Results, o.Results...)
+	r.Failures = append(r.Failures, o.Failures...)
+	r.ScanErrors = append(r.ScanErrors, o.ScanErrors...)
+	r.Interrupted = r.Interrupted || o.Interrupted
 }
 
 // BuildPlan evaluates every scanned file against the rule engine and returns
@@ -29,14 +100,80 @@ type Report struct {
 func BuildPlan(files []scanner.FileInfo, engine *rules.Engine) []MoveOp {
 	var ops []MoveOp
 	for _, f := range files {
-		rule := engine.Match(f)
-		if rule != nil {
-			ops = append(ops, MoveOp{
-				Source:      f.Path,
-				Destination: rule.Destination,
-				RuleName:    rule.Name,
-			})
+		if op, ok := planFile(f, engine); ok {
+			ops = append(ops, op)
 		}
 	}
 	return ops
 }
+
+// Plan is the streaming form of BuildPlan: it evaluates files as they are
+// yielded and yields a MoveOp for each match. Errors from files are passed
+// through unchanged.
+func Plan(files iter.Seq2[scanner.FileInfo, error], engine *rules.Engine) iter.Seq2[MoveOp, error] {
+	return PlanCached(files, engine, nil)
+}
+
+// PlanCached is Plan backed by a decision cache: a file whose entry in idx is
+// still valid reuses the cached rule instead of being evaluated again, and
+// every other decision is recorded in idx. A nil idx evaluates every file.
+func PlanCached(files iter.Seq2[scanner.FileInfo, error], engine *rules.Engine, idx *cache.Index) iter.Seq2[MoveOp, error] {
+	plan := planFile
+	if idx != nil {
+		plan = func(f scanner.FileInfo, engine *rules.Engine) (MoveOp, bool) {
+			return planCachedFile(f, engine, idx)
+		}
+	}
+
+	return func(yield func(MoveOp, error) bool) {
+		for f, err := range files {
+			if err != nil {
+				if !yield(MoveOp{}, err) {
+					return
+				}
+				continue
+			}
+			if op, ok := plan(f, engine); ok {
+				if !yield(op, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// planFile returns the MoveOp for f, or false if no rule matches it.
+func planFile(f scanner.FileInfo, engine *rules.Engine) (MoveOp, bool) {
+	rule := engine.Match(f)
+	if rule == nil {
+		return MoveOp{}, false
+	}
+	return newMoveOp(f, rule), true
+}
+
+// planCachedFile is planFile consulting idx before evaluating the rules.
+func planCachedFile(f scanner.FileInfo, engine *rules.Engine, idx *cache.Index) (MoveOp, bool) {
+	now := time.Now()
+	i, ok := idx.Lookup(f, now)
+	if !ok {
+		i = engine.MatchIndex(f)
+		idx.Record(f, i, engine.StableUntil(f, now))
+	}
+
+	all := engine.Rules()
+	if i < 0 || i >= len(all) {
+		return MoveOp{}, false
+	}
+	return newMoveOp(f, &all[i]), true
+}
+
+// newMoveOp returns the operation that moves f according to rule.
+func newMoveOp(f scanner.FileInfo, rule *rules.Rule) MoveOp {
+	return MoveOp{
+		Source:      f.Path,
+		Destination: rule.Destination,
+		RuleName:    rule.Name,
+		Symlink:     f.IsSymlink,
+		Size:        f.Size,
+	}
+}
@@ -0,0 +1,51 @@
+package organizer
+
+import (
+	"iter"
+
+	"github.com/devaloi/forg/internal/progress"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// observeScan passes files through unchanged, sending a Scanned event for
+// each one. ScanDone is left to the caller, which knows when the last
+// source has been scanned.
+func observeScan(files iter.Seq2[scanner.FileInfo, error], notify func(progress.Event)) iter.Seq2[scanner.FileInfo, error] {
+	return func(yield func(scanner.FileInfo, error) bool) {
+		for f, err := range files {
+			if err == nil {
+				notify(progress.Event{Kind: progress.Scanned, Path: f.Path, Size: f.Size})
+			}
+			if !yield(f, err) {
+				return
+			}
+		}
+	}
+}
+
+// observeScanDone passes ops, planned from the files of every source,
+// through unchanged and sends ScanDone once they have run to completion.
+func observeScanDone(ops iter.Seq2[MoveOp, error], notify func(progress.Event)) iter.Seq2[MoveOp, error] {
+	return func(yield func(MoveOp, error) bool) {
+		for op, err := range ops {
+			if !yield(op, err) {
+				return
+			}
+		}
+		notify(progress.Event{Kind: progress.ScanDone})
+	}
+}
+
+// observePlan passes ops through unchanged, sending a Matched event for each.
+func observePlan(ops iter.Seq2[MoveOp, error], notify func(progress.Event)) iter.Seq2[MoveOp, error] {
+	return func(yield func(MoveOp, error) bool) {
+		for op, err := range ops {
+			if err == nil {
+				notify(progress.Event{Kind: progress.Matched, Path: op.Source, Size: op.Size})
+			}
+			if !yield(op, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package organizer
+
+import (
+	"fmt"
+	"iter"
+	"path/filepath"
+	"strings"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/rules"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// Collision is a destination path that files from more than one source are
+// planned to move to. The conflict strategy decides what happens to all but
+// the first of them when they are moved.
+type Collision struct {
+	Destination string `json:"destination"`
+	// Files lists the first file planned for Destination, then every file
+	// planned for it once a second source did, in plan order.
+	Files []string `json:"files"`
+}
+
+// source is one directory Run organizes, with the scanner and rule engine
+// built for it.
+type source struct {
+	dir     string
+	engine  *rules.Engine
+	scanner *scanner.Scanner
+}
+
+// prepareSources builds the scanner and rule engine of every source of cfg.
+func prepareSources(cfg *config.Config, opts Options) ([]source, error) {
+	var sources []source
+	for _, src := range cfg.AllSources() {
+		engine, err := rules.NewEngine(cfg.RulesFor(src))
+		if err != nil {
+			return nil, fmt.Errorf("building rule engine for %s: %w", src.Path, err)
+		}
+		dir, err := config.ExpandPath(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("expanding source path: %w", err)
+		}
+		sources = append(sources, source{dir: dir, engine: engine})
+	}
+
+	// Never rescan files that earlier runs already sorted into a
+	// destination beneath any source, and leave a source nested inside
+	// another to its own scan so its files are planned once, with its own
+	// rules.
+	var skipDirs []string
+	for _, s := range sources {
+		skipDirs = append(skipDirs, s.dir)
+		for _, r := range s.engine.Rules() {
+			skipDirs = append(skipDirs, r.Destination)
+		}
+	}
+
+	for i, src := range cfg.AllSources() {
+		sources[i].scanner = newScanner(cfg, src, opts, skipDirs)
+	}
+	return sources, nil
+}
+
+// sourceFor returns the source whose directory most closely contains path,
+// or the first source when none does.
+func sourceFor(sources []source, path string) source {
+	best, depth := sources[0], -1
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return best
+	}
+	for _, s := range sources {
+		dir, err := filepath.Abs(s.dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(dir) > depth {
+			best, depth = s, len(dir)
+		}
+	}
+	return best
+}
+
+// collisionTracker records the first source each planned destination path
+// came from, to find paths targeted from more than one source. Only
+// destinations that collide keep a list of files.
+type collisionTracker struct {
+	first map[string]claimant
+	// index maps a colliding destination to its entry in found.
+	index map[string]int
+	found []Collision
+}
+
+// claimant is the first file planned for a destination, and its source.
+type claimant struct {
+	dir, file string
+}
+
+func newCollisionTracker() *collisionTracker {
+	return &collisionTracker{
+		first: make(map[string]claimant),
+		index: make(map[string]int),
+	}
+}
+
+// track passes ops through unchanged, recording each as planned from dir.
+func (t *collisionTracker) track(ops iter.Seq2[MoveOp, error], dir string) iter.Seq2[MoveOp, error] {
+	return func(yield func(MoveOp, error) bool) {
+		for op, err := range ops {
+			if err == nil {
+				t.add(filepath.Join(op.Destination, filepath.Base(op.Source)), dir, op.Source)
+			}
+			if !yield(op, err) {
+				return
+			}
+		}
+	}
+}
+
+// add records file, from the source dir, as planned for dest.
+func (t *collisionTracker) add(dest, dir, file string) {
+	if i, ok := t.index[dest]; ok {
+		t.found[i].Files = append(t.found[i].Files, file)
+		return
+	}
+	c, ok := t.first[dest]
+	switch {
+	case !ok:
+		t.first[dest] = claimant{dir: dir, file: file}
+	case c.dir != dir:
+		t.index[dest] = len(t.found)
+		t.found = append(t.found, Collision{Destination: dest, Files: []string{c.file, file}})
+	}
+}
+
+// collisions returns the destination paths planned from more than one
+// source, in the order they were found to collide.
+func (t *collisionTracker) collisions() []Collision {
+	return t.found
+}
+
+// chain yields the operations of each plan in turn.
+func chain(plans []iter.Seq2[MoveOp, error]) iter.Seq2[MoveOp, error] {
+	return func(yield func(MoveOp, error) bool) {
+		for _, plan := range plans {
+			for op, err := range plan {
+				if !yield(op, err) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -14,6 +14,10 @@ import (
 type UndoEntry struct {
 	From string `json:"from"`
 	To   string `json:"to"`
+	// Link is the original text of a moved symbolic link. The link is
+	// recreated with it on undo, since the moved link may have been
+	// rewritten to follow its target.
+	Link string `json:"link,omitempty"`
 }
 
 // UndoLog captures all operations from a single run together with metadata
@@ -111,7 +115,11 @@ func ExecuteUndo(log *UndoLog, verbose bool, logger func(string, ...interface{})
 			return fmt.Errorf("creating directory %s for undo: %w", dir, err)
 		}
 
-		if err := os.Rename(entry.To, entry.From); err != nil {
+		if entry.Link != "" {
+			if err := undoLink(entry); err != nil {
+				return err
+			}
+		} else if err := os.Rename(entry.To, entry.From); err != nil {
 			return fmt.Errorf("undoing move %s -> %s: %w", entry.To, entry.From, err)
 		}
 
@@ -122,3 +130,14 @@ func ExecuteUndo(log *UndoLog, verbose bool, logger func(string, ...interface{})
 
 	return nil
 }
+
+// undoLink restores the symbolic link moved by entry with its original text.
+func undoLink(entry UndoEntry) error {
+	if err := os.Symlink(entry.Link, entry.From); err != nil {
+		return fmt.Errorf("undoing move %s -> %s: %w", entry.To, entry.From, err)
+	}
+	if err := os.Remove(entry.To); err != nil {
+		return fmt.Errorf("undoing move %s -> %s: removing moved link: %w", entry.To, entry.From, err)
+	}
+	return nil
+}
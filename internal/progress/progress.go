@@ -0,0 +1,123 @@
+// Package progress defines the events a run emits as it scans, matches and
+// moves files, and a Tracker that turns them into running totals.
+package progress
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Kind identifies what an Event reports.
+type Kind int
+
+const (
+	// Scanned reports a file found by the scanner.
+	Scanned Kind = iota
+	// Matched reports a file that matched a rule and will be moved.
+	Matched
+	// Moved reports a matched file that was moved, or would be in a dry run.
+	Moved
+	// Skipped reports a matched file left in place because of a conflict.
+	Skipped
+	// Failed reports a matched file that could not be moved.
+	Failed
+	// ScanDone reports that the scan has finished, so the number of matched
+	// files is final.
+	ScanDone
+)
+
+// Event is a single progress update. Path and Size describe the file the
+// event is about and are empty for ScanDone.
+type Event struct {
+	Kind Kind
+	Path string
+	Size int64
+}
+
+// Snapshot holds the totals of a run at one point in time.
+type Snapshot struct {
+	Scanned int64
+	Matched int64
+	Moved   int64
+	Skipped int64
+	Failed  int64
+	// Bytes is the size of the files moved so far; TotalBytes is the size
+	// of every file matched so far.
+	Bytes      int64
+	TotalBytes int64
+	ScanDone   bool
+	Elapsed    time.Duration
+}
+
+// Done returns the number of matched files that have been dealt with.
+func (s Snapshot) Done() int64 { return s.Moved + s.Skipped + s.Failed }
+
+// ETA estimates the time left from the rate at which matched files have been
+// dealt with so far. It reports false until the scan has finished and at
+// least one file is done, since the total is not known before then.
+func (s Snapshot) ETA() (time.Duration, bool) {
+	done := s.Done()
+	if !s.ScanDone || done == 0 {
+		return 0, false
+	}
+	remaining := s.Matched - done
+	if remaining <= 0 {
+		return 0, true
+	}
+	return time.Duration(float64(s.Elapsed) / float64(done) * float64(remaining)), true
+}
+
+// Tracker accumulates events into running totals. It is safe for concurrent
+// use, so Observe can be handed directly to parallel workers.
+type Tracker struct {
+	start time.Time
+
+	scanned    atomic.Int64
+	matched    atomic.Int64
+	moved      atomic.Int64
+	skipped    atomic.Int64
+	failed     atomic.Int64
+	bytes      atomic.Int64
+	totalBytes atomic.Int64
+	scanDone   atomic.Bool
+}
+
+// NewTracker returns a Tracker whose elapsed time starts now.
+func NewTracker() *Tracker {
+	return &Tracker{start: time.Now()}
+}
+
+// Observe records ev.
+func (t *Tracker) Observe(ev Event) {
+	switch ev.Kind {
+	case Scanned:
+		t.scanned.Add(1)
+	case Matched:
+		t.matched.Add(1)
+		t.totalBytes.Add(ev.Size)
+	case Moved:
+		t.moved.Add(1)
+		t.bytes.Add(ev.Size)
+	case Skipped:
+		t.skipped.Add(1)
+	case Failed:
+		t.failed.Add(1)
+	case ScanDone:
+		t.scanDone.Store(true)
+	}
+}
+
+// Snapshot returns the current totals.
+func (t *Tracker) Snapshot() Snapshot {
+	return Snapshot{
+		Scanned:    t.scanned.Load(),
+		Matched:    t.matched.Load(),
+		Moved:      t.moved.Load(),
+		Skipped:    t.skipped.Load(),
+		Failed:     t.failed.Load(),
+		Bytes:      t.bytes.Load(),
+		TotalBytes: t.totalBytes.Load(),
+		ScanDone:   t.scanDone.Load(),
+		Elapsed:    time.Since(t.start),
+	}
+}
@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTracker_Observe(t *testing.T) {
+	tr := NewTracker()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			tr.Observe(Event{Kind: Scanned, Path: "/src/a", Size: 10})
+			tr.Observe(Event{Kind: Matched, Path: "/src/a", Size: 10})
+			tr.Observe(Event{Kind: Moved, Path: "/src/a", Size: 10})
+		})
+	}
+	wg.Wait()
+	tr.Observe(Event{Kind: Scanned, Path: "/src/b", Size: 5})
+	tr.Observe(Event{Kind: Matched, Path: "/src/b", Size: 5})
+	tr.Observe(Event{Kind: Skipped, Path: "/src/b", Size: 5})
+	tr.Observe(Event{Kind: ScanDone})
+
+	got := tr.Snapshot()
+	want := Snapshot{Scanned: 5, Matched: 5, Moved: 4, Skipped: 1, Bytes: 40, TotalBytes: 45, ScanDone: true}
+	got.Elapsed = 0
+	if got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+	if got.Done() != 5 {
+		t.Errorf("Done() = %d, want 5", got.Done())
+	}
+}
+
+func TestSnapshot_ETA(t *testing.T) {
+	tests := []struct {
+		name   string
+		snap   Snapshot
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name: "scan still running",
+			snap: Snapshot{Matched: 10, Moved: 5, Elapsed: time.Second},
+		},
+		{
+			name: "nothing done yet",
+			snap: Snapshot{Matched: 10, ScanDone: true, Elapsed: time.Second},
+		},
+		{
+			name:   "half done",
+			snap:   Snapshot{Matched: 10, Moved: 4, Failed: 1, ScanDone: true, Elapsed: 10 * time.Second},
+			want:   10 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "finished",
+			snap:   Snapshot{Matched: 3, Moved: 3, ScanDone: true, Elapsed: time.Second},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.snap.ETA()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ETA() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/devaloi/forg/internal/config"
 	"github.com/devaloi/forg/internal/scanner"
@@ -30,12 +31,46 @@ func NewEngine(cfgRules []config.RuleConfig) (*Engine, error) {
 // Match returns the first rule that matches the given file, or nil if no
 // rule matches.
 func (e *Engine) Match(file scanner.FileInfo) *Rule {
+	if i := e.MatchIndex(file); i >= 0 {
+		return &e.rules[i]
+	}
+	return nil
+}
+
+// MatchIndex returns the index in Rules of the first rule that matches the
+// given file, or -1 if no rule matches.
+func (e *Engine) MatchIndex(file scanner.FileInfo) int {
 	for i := range e.rules {
 		if e.rules[i].Match(file) {
-			return &e.rules[i]
+			return i
 		}
 	}
-	return nil
+	return -1
+}
+
+// StableUntil returns the earliest time after now at which an age matcher
+// could change its verdict on file, so a decision made at now holds until
+// then. It returns the zero time when no rule depends on the file's age.
+func (e *Engine) StableUntil(file scanner.FileInfo, now time.Time) time.Time {
+	var until time.Time
+	for _, r := range e.rules {
+		for _, m := range r.Matchers {
+			var seconds int64
+			switch m := m.(type) {
+			case OlderThanMatcher:
+				seconds = m.Seconds
+			case NewerThanMatcher:
+				seconds = m.Seconds
+			default:
+				continue
+			}
+			t := file.ModTime.Add(time.Duration(seconds) * time.Second)
+			if t.After(now) && (until.IsZero() || t.Before(until)) {
+				until = t
+			}
+		}
+	}
+	return until
 }
 
 // Rules returns all rules loaded into the engine.
@@ -100,5 +135,21 @@ func buildRule(cr config.RuleConfig) (Rule, error) {
 		r.Matchers = append(r.Matchers, NewerThanMatcher{Seconds: secs})
 	}
 
+	if cr.Match.Depth != "" {
+		minDepth, maxDepth, err := config.ParseDepth(cr.Match.Depth)
+		if err != nil {
+			return Rule{}, fmt.Errorf("parsing depth: %w", err)
+		}
+		r.Matchers = append(r.Matchers, DepthMatcher{Min: minDepth, Max: maxDepth})
+	}
+
+	if cr.Match.BrokenSymlink {
+		r.Matchers = append(r.Matchers, BrokenSymlinkMatcher{})
+	}
+
+	// Always constrain the type so that special files only reach rules
+	// that list them under types.
+	r.Matchers = append(r.Matchers, TypeMatcher{Types: cr.Match.Types})
+
 	return r, nil
 }
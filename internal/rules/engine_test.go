@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -236,6 +237,101 @@ func TestNewerThanMatcher(t *testing.T) {
 	}
 }
 
+func TestDepthMatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		min   int
+		max   int
+		depth int
+		want  bool
+	}{
+		{"exact depth match", 2, 2, 2, true},
+		{"shallower than range", 2, 3, 1, false},
+		{"deeper than range", 2, 3, 4, false},
+		{"open-ended range", 2, 0, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := DepthMatcher{Min: tt.min, Max: tt.max}
+			got := m.Match(scanner.FileInfo{Depth: tt.depth})
+			if got != tt.want {
+				t.Errorf("DepthMatcher.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokenSymlinkMatcher(t *testing.T) {
+	tests := []struct {
+		name string
+		file scanner.FileInfo
+		want bool
+	}{
+		{"broken link", scanner.FileInfo{IsSymlink: true, Broken: true}, true},
+		{"working link", scanner.FileInfo{IsSymlink: true}, false},
+		{"regular file", scanner.FileInfo{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BrokenSymlinkMatcher{}.Match(tt.file)
+			if got != tt.want {
+				t.Errorf("BrokenSymlinkMatcher.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeMatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+		file  scanner.FileInfo
+		want  bool
+	}{
+		{"default accepts regular files", nil, scanner.FileInfo{Type: "file"}, true},
+		{"default accepts symlinks", nil, scanner.FileInfo{Type: "symlink"}, true},
+		{"default rejects fifos", nil, scanner.FileInfo{Type: "fifo"}, false},
+		{"explicit fifo", []string{"fifo", "socket"}, scanner.FileInfo{Type: "fifo"}, true},
+		{"explicit list rejects others", []string{"socket"}, scanner.FileInfo{Type: "file"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := TypeMatcher{Types: tt.types}
+			if got := m.Match(tt.file); got != tt.want {
+				t.Errorf("TypeMatcher.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_SpecialFilesNeedExplicitType(t *testing.T) {
+	cfgRules := []config.RuleConfig{
+		{
+			Name:        "logs",
+			Match:       config.MatchConfig{Pattern: "*.log"},
+			Destination: "/logs",
+		},
+		{
+			Name:        "pipes",
+			Match:       config.MatchConfig{Types: []string{"fifo"}},
+			Destination: "/pipes",
+		},
+	}
+
+	engine, err := NewEngine(cfgRules)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	matched := engine.Match(scanner.FileInfo{Name: "app.log", Type: "fifo"})
+	if matched == nil || matched.Name != "pipes" {
+		t.Errorf("expected fifo to skip the logs rule and match pipes, got %v", matched)
+	}
+}
+
 func TestRule_ANDLogic(t *testing.T) {
 	rule := Rule{
 		Name:        "images-large",
@@ -380,3 +476,236 @@ func TestNewEngine_InvalidSize(t *testing.T) {
 		t.Error("expected error for invalid min_size, got nil")
 	}
 }
+
+func TestEngine_StableUntil(t *testing.T) {
+	now := time.Now()
+	mtime := now.Add(-10 * 24 * time.Hour)
+	file := scanner.FileInfo{Name: "a.txt", Extension: ".txt", ModTime: mtime}
+
+	tests := []struct {
+		name  string
+		match []config.MatchConfig
+		want  time.Time
+	}{
+		{
+			name:  "no age criteria",
+			match: []config.MatchConfig{{Extensions: []string{".txt"}}},
+		},
+		{
+			name:  "threshold in the future",
+			match: []config.MatchConfig{{OlderThan: "30d"}},
+			want:  mtime.Add(30 * 24 * time.Hour),
+		},
+		{
+			name:  "threshold already passed",
+			match: []config.MatchConfig{{NewerThan: "7d"}},
+		},
+		{
+			name:  "earliest threshold wins",
+			match: []config.MatchConfig{{OlderThan: "2w"}, {NewerThan: "30d"}},
+			want:  mtime.Add(14 * 24 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfgRules []config.RuleConfig
+			for _, m := range tt.match {
+				cfgRules = append(cfgRules, config.RuleConfig{Name: "r", Match: m, Destination: "/dest"})
+			}
+			engine, err := NewEngine(cfgRules)
+			if err != nil {
+				t.Fatalf("NewEngine: %v", err)
+			}
+
+			if got := engine.StableUntil(file, now); !got.Equal(tt.want) {
+				t.Errorf("StableUntil() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShadows(t *testing.T) {
+	tests := []struct {
+		name    string
+		earlier config.MatchConfig
+		later   config.MatchConfig
+		want    bool
+	}{
+		{
+			name:    "superset of extensions",
+			earlier: config.MatchConfig{Extensions: []string{".jpg", ".png"}},
+			later:   config.MatchConfig{Extensions: []string{".JPG"}},
+			want:    true,
+		},
+		{
+			name:    "later adds extensions",
+			earlier: config.MatchConfig{Extensions: []string{".jpg"}},
+			later:   config.MatchConfig{Extensions: []string{".jpg", ".png"}},
+			want:    false,
+		},
+		{
+			name:    "later is narrower by size",
+			earlier: config.MatchConfig{Extensions: []string{".mp4"}},
+			later:   config.MatchConfig{Extensions: []string{".mp4"}, MinSize: "1GB"},
+			want:    true,
+		},
+		{
+			name:    "earlier is narrower by size",
+			earlier: config.MatchConfig{Extensions: []string{".mp4"}, MinSize: "1GB"},
+			later:   config.MatchConfig{Extensions: []string{".mp4"}},
+			want:    false,
+		},
+		{
+			name:    "size ranges",
+			earlier: config.MatchConfig{MinSize: "1MB", MaxSize: "1GB"},
+			later:   config.MatchConfig{MinSize: "10MB", MaxSize: "100MB"},
+			want:    true,
+		},
+		{
+			name:    "age thresholds",
+			earlier: config.MatchConfig{OlderThan: "30d"},
+			later:   config.MatchConfig{OlderThan: "1y", Pattern: "*.log"},
+			want:    true,
+		},
+		{
+			name:    "newer than is wider",
+			earlier: config.MatchConfig{NewerThan: "1w"},
+			later:   config.MatchConfig{NewerThan: "30d"},
+			want:    false,
+		},
+		{
+			name:    "depth within range",
+			earlier: config.MatchConfig{Depth: "1-3"},
+			later:   config.MatchConfig{Depth: "2"},
+			want:    true,
+		},
+		{
+			name:    "open depth range",
+			earlier: config.MatchConfig{Depth: "1-3"},
+			later:   config.MatchConfig{Depth: "2+"},
+			want:    false,
+		},
+		{
+			name:    "different patterns",
+			earlier: config.MatchConfig{Pattern: "*.log"},
+			later:   config.MatchConfig{Pattern: "app-*.log"},
+			want:    false,
+		},
+		{
+			name:    "special types are not shadowed by default types",
+			earlier: config.MatchConfig{Pattern: "*"},
+			later:   config.MatchConfig{Pattern: "*", Types: []string{"fifo"}},
+			want:    false,
+		},
+		{
+			name:    "explicit regular type is shadowed by default types",
+			earlier: config.MatchConfig{Pattern: "*"},
+			later:   config.MatchConfig{Pattern: "*", Types: []string{"file"}},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine([]config.RuleConfig{
+				{Name: "earlier", Match: tt.earlier, Destination: "/tmp/a"},
+				{Name: "later", Match: tt.later, Destination: "/tmp/b"},
+			})
+			if err != nil {
+				t.Fatalf("NewEngine() error: %v", err)
+			}
+			rules := engine.Rules()
+			if got := Shadows(&rules[0], &rules[1]); got != tt.want {
+				t.Errorf("Shadows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Explain(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	engine, err := NewEngine([]config.RuleConfig{
+		{Name: "big-images", Match: config.MatchConfig{Extensions: []string{".jpg"}, MinSize: "1MB"}, Destination: "/tmp/big"},
+		{Name: "old", Match: config.MatchConfig{OlderThan: "30d"}, Destination: "/tmp/old"},
+		{Name: "logs", Match: config.MatchConfig{Pattern: "*.log"}, Destination: "/tmp/logs"},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error: %v", err)
+	}
+
+	file := scanner.FileInfo{
+		Name:      "photo.jpg",
+		Extension: ".jpg",
+		Size:      2048,
+		ModTime:   now.AddDate(0, 0, -45),
+		Depth:     1,
+		Type:      "file",
+	}
+	traces := engine.Explain(file, now)
+	if len(traces) != 3 {
+		t.Fatalf("Explain() returned %d traces, want 3", len(traces))
+	}
+
+	tests := []struct {
+		rule     int
+		matched  bool
+		verdicts []Verdict
+	}{
+		{0, false, []Verdict{
+			{Criterion: "extensions", Matched: true, Detail: ".jpg in [.jpg]"},
+			{Criterion: "min_size", Matched: false, Detail: "2.0 KB >= 1.0 MB"},
+			{Criterion: "types", Matched: true, Detail: "file in any non-special type"},
+		}},
+		{1, true, []Verdict{
+			{Criterion: "older_than", Matched: true},
+			{Criterion: "types", Matched: true},
+		}},
+		{2, false, []Verdict{
+			{Criterion: "pattern", Matched: false, Detail: "photo.jpg matches *.log"},
+			{Criterion: "types", Matched: true},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := traces[tt.rule]
+		if got.Matched != tt.matched {
+			t.Errorf("rule %d: Matched = %v, want %v", tt.rule, got.Matched, tt.matched)
+		}
+		if len(got.Verdicts) != len(tt.verdicts) {
+			t.Fatalf("rule %d: %d verdicts, want %d", tt.rule, len(got.Verdicts), len(tt.verdicts))
+		}
+		for i, want := range tt.verdicts {
+			v := got.Verdicts[i]
+			if v.Criterion != want.Criterion || v.Matched != want.Matched || (want.Detail != "" && v.Detail != want.Detail) {
+				t.Errorf("rule %d verdict %d = %+v, want %+v", tt.rule, i, v, want)
+			}
+		}
+	}
+
+	if d := traces[1].Verdicts[0].Detail; !strings.Contains(d, "age 45d") {
+		t.Errorf("older_than detail = %q, want it to show the age", d)
+	}
+}
+
+func TestEngine_ExplainAt(t *testing.T) {
+	// now is long past, so judging the age at the wall clock instead would
+	// find the file old.
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	engine, err := NewEngine([]config.RuleConfig{
+		{Name: "recent", Match: config.MatchConfig{NewerThan: "30d"}, Destination: "/tmp/recent"},
+		{Name: "old", Match: config.MatchConfig{OlderThan: "30d"}, Destination: "/tmp/old"},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error: %v", err)
+	}
+
+	file := scanner.FileInfo{Name: "a.txt", Extension: ".txt", ModTime: now.AddDate(0, 0, -1), Depth: 1, Type: "file"}
+	traces := engine.Explain(file, now)
+	if !traces[0].Matched || !traces[0].Verdicts[0].Matched {
+		t.Errorf("recent: Matched = %v, verdict %+v; want both to match at now", traces[0].Matched, traces[0].Verdicts[0])
+	}
+	if traces[1].Matched || traces[1].Verdicts[0].Matched {
+		t.Errorf("old: Matched = %v, verdict %+v; want neither to match at now", traces[1].Matched, traces[1].Verdicts[0])
+	}
+}
@@ -0,0 +1,124 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// Verdict is one matcher's decision on a file, with the values it compared.
+type Verdict struct {
+	// Criterion names the match field, e.g. "min_size" or "pattern".
+	Criterion string
+	Matched   bool
+	// Detail shows the file's value against the rule's, e.g.
+	// "4.0 MB >= 1.0 MB".
+	Detail string
+}
+
+// Trace records how one rule evaluated a file.
+type Trace struct {
+	Rule     *Rule
+	Matched  bool
+	Verdicts []Verdict
+}
+
+// Explain evaluates file against every rule, in order, and returns one trace
+// per rule. Unlike Match it does not stop at the first matching rule, so the
+// caller can show what later rules would have decided. Age criteria are
+// described relative to now.
+func (e *Engine) Explain(file scanner.FileInfo, now time.Time) []Trace {
+	traces := make([]Trace, 0, len(e.rules))
+	for i := range e.rules {
+		r := &e.rules[i]
+		// Matched follows from the verdicts, as Rule.Match does, so age
+		// criteria are judged once, at now.
+		t := Trace{Rule: r, Matched: len(r.Matchers) > 0}
+		for _, m := range r.Matchers {
+			v := explain(m, file, now)
+			t.Matched = t.Matched && v.Matched
+			t.Verdicts = append(t.Verdicts, v)
+		}
+		traces = append(traces, t)
+	}
+	return traces
+}
+
+// timedMatcher is a Matcher whose verdict depends on the current time.
+type timedMatcher interface {
+	MatchAt(file scanner.FileInfo, now time.Time) bool
+}
+
+// explain describes m's verdict on file, judging age criteria at now.
+func explain(m Matcher, file scanner.FileInfo, now time.Time) Verdict {
+	v := Verdict{Matched: m.Match(file)}
+	if tm, ok := m.(timedMatcher); ok {
+		v.Matched = tm.MatchAt(file, now)
+	}
+	switch m := m.(type) {
+	case ExtensionMatcher:
+		v.Criterion = "extensions"
+		v.Detail = fmt.Sprintf("%s in [%s]", orNone(file.Extension), strings.Join(m.Extensions, ", "))
+	case PatternMatcher:
+		v.Criterion = "pattern"
+		v.Detail = fmt.Sprintf("%s matches %s", file.Name, m.Pattern)
+	case MinSizeMatcher:
+		v.Criterion = "min_size"
+		v.Detail = fmt.Sprintf("%s >= %s", config.FormatSize(file.Size), config.FormatSize(m.MinBytes))
+	case MaxSizeMatcher:
+		v.Criterion = "max_size"
+		v.Detail = fmt.Sprintf("%s <= %s", config.FormatSize(file.Size), config.FormatSize(m.MaxBytes))
+	case OlderThanMatcher:
+		v.Criterion = "older_than"
+		cutoff := now.Add(-time.Duration(m.Seconds) * time.Second)
+		v.Detail = fmt.Sprintf("modified %s before cutoff %s (age %s)", formatTime(file.ModTime), formatTime(cutoff), formatAge(now.Sub(file.ModTime)))
+	case NewerThanMatcher:
+		v.Criterion = "newer_than"
+		cutoff := now.Add(-time.Duration(m.Seconds) * time.Second)
+		v.Detail = fmt.Sprintf("modified %s after cutoff %s (age %s)", formatTime(file.ModTime), formatTime(cutoff), formatAge(now.Sub(file.ModTime)))
+	case DepthMatcher:
+		v.Criterion = "depth"
+		upper := "any"
+		if m.Max > 0 {
+			upper = fmt.Sprint(m.Max)
+		}
+		v.Detail = fmt.Sprintf("depth %d in %d..%s", file.Depth, m.Min, upper)
+	case BrokenSymlinkMatcher:
+		v.Criterion = "broken_symlink"
+		v.Detail = fmt.Sprintf("symlink %t, broken %t", file.IsSymlink, file.Broken)
+	case TypeMatcher:
+		v.Criterion = "types"
+		want := "any non-special type"
+		if len(m.Types) > 0 {
+			want = "[" + strings.Join(m.Types, ", ") + "]"
+		}
+		v.Detail = fmt.Sprintf("%s in %s", file.Type, want)
+	default:
+		v.Criterion = fmt.Sprintf("%T", m)
+	}
+	return v
+}
+
+// orNone returns s, or "(none)" when it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// formatTime renders t to the minute in local time.
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatAge renders d in whole days, or hours and minutes when shorter.
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.Round(time.Minute).String()
+}
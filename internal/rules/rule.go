@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/devaloi/forg/internal"
 	"github.com/devaloi/forg/internal/scanner"
 )
 
@@ -76,7 +77,12 @@ type OlderThanMatcher struct {
 
 // Match returns true if the file's modification time is older than the threshold.
 func (m OlderThanMatcher) Match(file scanner.FileInfo) bool {
-	threshold := time.Now().Add(-time.Duration(m.Seconds) * time.Second)
+	return m.MatchAt(file, time.Now())
+}
+
+// MatchAt is Match with the threshold measured back from now.
+func (m OlderThanMatcher) MatchAt(file scanner.FileInfo, now time.Time) bool {
+	threshold := now.Add(-time.Duration(m.Seconds) * time.Second)
 	return file.ModTime.Before(threshold)
 }
 
@@ -88,10 +94,58 @@ type NewerThanMatcher struct {
 
 // Match returns true if the file's modification time is newer than the threshold.
 func (m NewerThanMatcher) Match(file scanner.FileInfo) bool {
-	threshold := time.Now().Add(-time.Duration(m.Seconds) * time.Second)
+	return m.MatchAt(file, time.Now())
+}
+
+// MatchAt is Match with the threshold measured back from now.
+func (m NewerThanMatcher) MatchAt(file scanner.FileInfo, now time.Time) bool {
+	threshold := now.Add(-time.Duration(m.Seconds) * time.Second)
 	return file.ModTime.After(threshold)
 }
 
+// DepthMatcher matches files whose depth below the source directory lies
+// within [Min, Max]. A Max of zero means no upper bound.
+type DepthMatcher struct {
+	Min int
+	Max int
+}
+
+// Match returns true if the file's depth is within the configured range.
+func (m DepthMatcher) Match(file scanner.FileInfo) bool {
+	if file.Depth < m.Min {
+		return false
+	}
+	return m.Max == 0 || file.Depth <= m.Max
+}
+
+// BrokenSymlinkMatcher matches symbolic links whose target does not exist.
+type BrokenSymlinkMatcher struct{}
+
+// Match returns true if the file is a dangling symbolic link.
+func (BrokenSymlinkMatcher) Match(file scanner.FileInfo) bool {
+	return file.IsSymlink && file.Broken
+}
+
+// TypeMatcher matches files whose type appears in Types. An empty Types list
+// matches every non-special type, so FIFOs, sockets and device nodes only
+// match rules that ask for them explicitly.
+type TypeMatcher struct {
+	Types []string
+}
+
+// Match returns true if the file's type is accepted.
+func (m TypeMatcher) Match(file scanner.FileInfo) bool {
+	if len(m.Types) == 0 {
+		return !internal.SpecialFileType(file.Type)
+	}
+	for _, t := range m.Types {
+		if t == file.Type {
+			return true
+		}
+	}
+	return false
+}
+
 // Rule represents a named organization rule that maps matching files to a
 // destination directory.
 type Rule struct {
@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/devaloi/forg/internal"
+)
+
+// Shadows reports whether every file matched by later is also matched by
+// earlier, so that later can never win when earlier comes first. The check is
+// conservative: it compares matchers of the same kind and may miss overlaps
+// that only arise from combining kinds.
+func Shadows(earlier, later *Rule) bool {
+	if len(earlier.Matchers) == 0 {
+		return false
+	}
+	for _, m := range earlier.Matchers {
+		if !slices.ContainsFunc(later.Matchers, func(n Matcher) bool { return implies(n, m) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// implies reports whether every file accepted by n is also accepted by m.
+func implies(n, m Matcher) bool {
+	switch m := m.(type) {
+	case ExtensionMatcher:
+		n, ok := n.(ExtensionMatcher)
+		return ok && subsetFold(n.Extensions, m.Extensions)
+	case PatternMatcher:
+		n, ok := n.(PatternMatcher)
+		return ok && n.Pattern == m.Pattern
+	case MinSizeMatcher:
+		n, ok := n.(MinSizeMatcher)
+		return ok && n.MinBytes >= m.MinBytes
+	case MaxSizeMatcher:
+		n, ok := n.(MaxSizeMatcher)
+		return ok && n.MaxBytes <= m.MaxBytes
+	case OlderThanMatcher:
+		n, ok := n.(OlderThanMatcher)
+		return ok && n.Seconds >= m.Seconds
+	case NewerThanMatcher:
+		n, ok := n.(NewerThanMatcher)
+		return ok && n.Seconds <= m.Seconds
+	case DepthMatcher:
+		n, ok := n.(DepthMatcher)
+		return ok && n.Min >= m.Min && (m.Max == 0 || n.Max != 0 && n.Max <= m.Max)
+	case BrokenSymlinkMatcher:
+		_, ok := n.(BrokenSymlinkMatcher)
+		return ok
+	case TypeMatcher:
+		n, ok := n.(TypeMatcher)
+		if !ok {
+			return false
+		}
+		if len(m.Types) == 0 {
+			return !slices.ContainsFunc(n.Types, internal.SpecialFileType)
+		}
+		return len(n.Types) > 0 && subsetFold(n.Types, m.Types)
+	default:
+		return false
+	}
+}
+
+// subsetFold reports whether every element of sub appears in set, ignoring
+// case.
+func subsetFold(sub, set []string) bool {
+	for _, s := range sub {
+		if !slices.ContainsFunc(set, func(t string) bool { return strings.EqualFold(s, t) }) {
+			return false
+		}
+	}
+	return true
+}
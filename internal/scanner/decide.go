@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/ignore"
+)
+
+// outcome is what the walk does with a directory entry. The walk and
+// Describe both take it from decide, so explaining a path always agrees with
+// scanning it.
+type outcome int
+
+const (
+	// emitEntry reports the entry.
+	emitEntry outcome = iota
+	// emitWhole reports an opaque directory as a single entry.
+	emitWhole
+	// descend walks into the directory.
+	descend
+	// The remaining outcomes leave the entry out, for the reason named.
+	skipHidden
+	skipLink
+	skipExcluded
+	skipDestination
+	skipNotRecursive
+	skipMaxDepth
+	skipIgnoreFile
+	skipType
+	skipDepth
+)
+
+// candidate is a directory entry the walk has to decide on.
+type candidate struct {
+	name  string
+	path  string
+	depth int
+	// link is true for a symbolic link. Its isDir and typ only reflect the
+	// target once the link has been resolved.
+	link bool
+	// isDir is true when the walk treats the entry as a directory,
+	// including a followed link to one.
+	isDir bool
+	// typ is the internal.FileType* name the entry is reported with.
+	typ string
+}
+
+// decide applies the scan options to c, where stack holds the exclude
+// patterns in effect in c's directory. Hidden entries and links under the
+// skip policy are decided on the name and kind alone, so a link only has to
+// be resolved when decide does not skip it for either reason.
+func (s *Scanner) decide(c candidate, stack, opaque ignore.Stack, skipDirs map[string]bool) outcome {
+	switch {
+	case !s.opts.IncludeHidden && strings.HasPrefix(c.name, "."):
+		return skipHidden
+	case c.link && (s.opts.Symlinks == "" || s.opts.Symlinks == internal.SymlinkSkip):
+		return skipLink
+	case stack.Ignored(c.path, c.isDir):
+		return skipExcluded
+	}
+
+	if !c.isDir {
+		switch {
+		case c.name == internal.IgnoreFile:
+			return skipIgnoreFile
+		case !s.typeAllowed(c.typ):
+			return skipType
+		case !s.depthAllowed(c.depth):
+			return skipDepth
+		}
+		return emitEntry
+	}
+
+	switch {
+	case skipDirs[c.path]:
+		return skipDestination
+	case opaque.Ignored(c.path, true):
+		if !s.depthAllowed(c.depth) {
+			return skipDepth
+		}
+		return emitWhole
+	case !s.opts.Recursive:
+		return skipNotRecursive
+	case s.opts.MaxDepth > 0 && c.depth >= s.opts.MaxDepth:
+		return skipMaxDepth
+	}
+	return descend
+}
+
+// applyLinkPolicy returns link, a resolved symbolic link, as the walk reports
+// it, and whether the walk descends into it as a directory.
+func (s *Scanner) applyLinkPolicy(link FileInfo) (FileInfo, bool) {
+	policy := s.opts.Symlinks
+	if policy == internal.SymlinkFollow && !link.Broken && link.IsDir {
+		return link, true
+	}
+	if policy == internal.SymlinkMoveLink || link.Broken {
+		link.Type = internal.FileTypeSymlink
+	}
+	return link, false
+}
@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/ignore"
+)
+
+// Describe returns the FileInfo a scan of source would report for path,
+// without walking the rest of the tree. The second result explains why the
+// scan would leave the entry out, such as an exclude pattern or the depth
+// limits; it is empty when the scan would yield the entry. Every directory
+// on the way to path is checked the way the walk would check it, including
+// the symlink policy for linked directories.
+func (s *Scanner) Describe(source, path string) (FileInfo, string, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return FileInfo{}, "", fmt.Errorf("scanner: resolve source %q: %w", source, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return FileInfo{}, "", fmt.Errorf("scanner: resolve %q: %w", path, err)
+	}
+
+	lst, err := os.Lstat(absPath)
+	if err != nil {
+		return FileInfo{}, "", fmt.Errorf("scanner: file info %q: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(absSource, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return newFileInfo(absPath, lst, 0), "it is not inside the source directory", nil
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+
+	fi, c, err := s.describeEntry(absPath, lst, len(parts))
+	if err != nil {
+		return FileInfo{}, "", err
+	}
+
+	stack, err := s.rootIgnores(absSource)
+	if err != nil {
+		return FileInfo{}, "", err
+	}
+	opaque, err := ignore.NewList(absSource, s.opts.Opaque)
+	if err != nil {
+		return FileInfo{}, "", fmt.Errorf("scanner: opaque: %w", err)
+	}
+	opaqueStack := ignore.Stack{opaque}
+	skipDirs := s.skipDirs(absSource)
+
+	// Replay the walk's decision for each directory on the way to path.
+	dir := absSource
+	for i, name := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, name)
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return FileInfo{}, "", fmt.Errorf("scanner: file info %q: %w", dir, err)
+		}
+		_, dc, err := s.describeEntry(dir, info, i+1)
+		if err != nil {
+			return FileInfo{}, "", err
+		}
+		if o := s.decide(dc, stack, opaqueStack, skipDirs); o != descend {
+			return fi, s.dirReason(dir, o), nil
+		}
+
+		list, err := ignore.Load(dir, internal.IgnoreFile)
+		if err != nil {
+			return FileInfo{}, "", fmt.Errorf("scanner: %w", err)
+		}
+		stack = stack.Push(list)
+	}
+
+	return fi, s.entryReason(fi, s.decide(c, stack, opaqueStack, skipDirs)), nil
+}
+
+// describeEntry builds the FileInfo for the entry at path, applying the
+// symlink policy as readDir does, and the candidate the walk decides on.
+func (s *Scanner) describeEntry(path string, lst fs.FileInfo, depth int) (FileInfo, candidate, error) {
+	c := candidate{
+		name:  lst.Name(),
+		path:  path,
+		depth: depth,
+		link:  lst.Mode()&fs.ModeSymlink != 0,
+		isDir: lst.IsDir(),
+		typ:   typeOf(lst.Mode()),
+	}
+	if !c.link {
+		return newFileInfo(path, lst, depth), c, nil
+	}
+
+	link, err := resolveLink(path, fs.FileInfoToDirEntry(lst))
+	if err != nil {
+		return FileInfo{}, candidate{}, err
+	}
+	link, c.isDir = s.applyLinkPolicy(link)
+	link.Depth = depth
+	c.typ = link.Type
+	return link, c, nil
+}
+
+// dirReason explains why the walk, having reached dir with outcome o, never
+// reaches anything beneath it.
+func (s *Scanner) dirReason(dir string, o outcome) string {
+	switch o {
+	case skipHidden:
+		return fmt.Sprintf("directory %s is hidden (use --include-hidden)", dir)
+	case skipLink:
+		return fmt.Sprintf("directory %s is a symbolic link and symbolic links are skipped (set symlinks: follow)", dir)
+	case skipExcluded:
+		return fmt.Sprintf("directory %s is excluded", dir)
+	case skipDestination:
+		return fmt.Sprintf("directory %s is skipped as a rule destination or another source", dir)
+	case emitWhole, skipDepth:
+		return fmt.Sprintf("directory %s is opaque and handled as a whole", dir)
+	case skipNotRecursive:
+		return "it is in a subdirectory and the scan is not recursive (use --recursive)"
+	case skipMaxDepth:
+		return fmt.Sprintf("it is deeper than max_depth %d", s.opts.MaxDepth)
+	default:
+		return fmt.Sprintf("directory %s is a symbolic link moved as a single entry (set symlinks: follow)", dir)
+	}
+}
+
+// entryReason explains outcome o for the entry fi itself, or returns "" when
+// the walk reports it.
+func (s *Scanner) entryReason(fi FileInfo, o outcome) string {
+	switch o {
+	case emitEntry, emitWhole:
+		return ""
+	case skipHidden:
+		return "it is hidden (use --include-hidden)"
+	case skipLink:
+		return "symbolic links are skipped (set symlinks: move-link or follow)"
+	case skipExcluded:
+		return "it is excluded"
+	case skipDestination:
+		return "it is skipped as a rule destination or another source"
+	case skipIgnoreFile:
+		return "it is an ignore file"
+	case skipType:
+		return fmt.Sprintf("no rule asks for %s entries under types", fi.Type)
+	case skipDepth:
+		return fmt.Sprintf("depth %d is outside min_depth %d and max_depth %d", fi.Depth, s.opts.MinDepth, s.opts.MaxDepth)
+	default:
+		return "it is a directory; only the files inside it are organised"
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// inodeOf returns zero: inode numbers are not available on this platform.
+func inodeOf(fs.FileInfo) uint64 { return 0 }
@@ -0,0 +1,120 @@
+package scanner
+
+import "sync"
+
+// dirQueue is the shared work list of a parallel walk. pending counts the
+// directories that are queued or being read; the walk is over once it drops
+// to zero.
+type dirQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	tasks   []dirTask
+	pending int
+	err     error
+}
+
+func newDirQueue(root dirTask) *dirQueue {
+	q := &dirQueue{tasks: []dirTask{root}, pending: 1}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// next blocks until a directory is available and returns it. It returns
+// false once the walk has finished or been stopped.
+func (q *dirQueue) next() (dirTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.tasks) == 0 && q.pending > 0 && q.err == nil {
+		q.cond.Wait()
+	}
+	if q.err != nil || len(q.tasks) == 0 {
+		return dirTask{}, false
+	}
+
+	// Taking the newest task keeps the walk roughly depth-first, so the
+	// queue holds one level of listings per depth rather than a whole
+	// breadth of the tree.
+	t := q.tasks[len(q.tasks)-1]
+	q.tasks = q.tasks[:len(q.tasks)-1]
+	return t, true
+}
+
+// finish records that a directory has been read and queues its
+// subdirectories. A non-nil err stops the walk.
+func (q *dirQueue) finish(subdirs []dirTask, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err != nil {
+		q.stopLocked(err)
+	}
+	q.tasks = append(q.tasks, subdirs...)
+	q.pending += len(subdirs) - 1
+	q.cond.Broadcast()
+}
+
+// stop ends the walk with err; directories already being read finish first.
+func (q *dirQueue) stop(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopLocked(err)
+	q.cond.Broadcast()
+}
+
+func (q *dirQueue) stopLocked(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// walkParallel walks the tree below root with up to Options.Workers
+// goroutines reading directories at once. Entries are passed to the
+// consumer on the calling goroutine, so it never sees concurrent calls,
+// and a small buffer keeps the workers from running far ahead of it.
+func (s *Scanner) walkParallel(w *walk, root dirTask) error {
+	type result struct {
+		fi  FileInfo
+		err error
+	}
+
+	results := make(chan result, s.opts.Workers)
+	done := make(chan struct{})
+	consumer := w.yield
+	w.yield = func(fi FileInfo, err error) bool {
+		select {
+		case results <- result{fi: fi, err: err}:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	q := newDirQueue(root)
+	var wg sync.WaitGroup
+	for range s.opts.Workers {
+		wg.Go(func() {
+			for {
+				t, ok := q.next()
+				if !ok {
+					return
+				}
+				subdirs, err := s.readDir(w, t)
+				q.finish(subdirs, err)
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		if !consumer(r.fi, r.err) {
+			close(done)
+			q.stop(errStop)
+			for range results {
+			}
+			return errStop
+		}
+	}
+	return q.err
+}
@@ -2,12 +2,20 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"iter"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/devaloi/forg/internal"
+	"github.com/devaloi/forg/internal/ignore"
 )
 
 // FileInfo holds metadata about a single file discovered during a scan.
@@ -17,6 +25,24 @@ type FileInfo struct {
 	Extension string
 	Size      int64
 	ModTime   time.Time
+	// Depth is the entry's depth below the source directory; entries
+	// directly inside the source have depth 1.
+	Depth int
+	// IsDir is true for opaque directories reported as a single unit.
+	IsDir bool
+	// IsSymlink is true when Path is a symbolic link. Size and ModTime then
+	// describe the link target when it can be resolved.
+	IsSymlink bool
+	// LinkTarget is the raw target of a symbolic link.
+	LinkTarget string
+	// Broken is true for symbolic links whose target does not exist.
+	Broken bool
+	// Type classifies the entry, e.g. "file", "dir", "symlink" or "fifo".
+	// Followed links report the type of their target.
+	Type string
+	// Inode is the file's inode number, or zero where the platform does
+	// not expose one.
+	Inode uint64
 }
 
 // Options controls the behaviour of a Scanner.
@@ -26,6 +52,62 @@ type Options struct {
 	Recursive bool
 	// IncludeHidden includes files whose names start with ".".
 	IncludeHidden bool
+	// Exclude lists gitignore-style patterns, relative to the source
+	// directory, for files and directories to skip. Patterns from
+	// .forgignore files found in the tree are applied after these.
+	Exclude []string
+	// SkipDirs lists directories that are never descended into, typically
+	// rule destinations and other source directories that live under the
+	// source directory. Entries that are not under the source are ignored.
+	SkipDirs []string
+	// Opaque lists gitignore-style patterns for directories that are
+	// reported as a single entry instead of being descended into.
+	Opaque []string
+	// MinDepth skips files shallower than this depth. Zero disables the limit.
+	MinDepth int
+	// MaxDepth stops a recursive scan from descending below this depth.
+	// Zero means unlimited.
+	MaxDepth int
+	// Symlinks selects how symbolic links are handled: skip (the default),
+	// move-link, or follow.
+	Symlinks string
+	// Types lists special file types (fifo, socket, device, char-device,
+	// irregular) to include. Other special files are skipped.
+	Types []string
+	// Tolerant records per-path errors (unreadable directories, entries
+	// removed mid-scan) and keeps walking instead of aborting the scan.
+	// Errors for the source directory itself are always fatal.
+	Tolerant bool
+	// Workers is the number of directories a recursive scan reads
+	// concurrently. Values below 2 walk the tree sequentially. A parallel
+	// walk finds the same entries as a sequential one, in no fixed order.
+	Workers int
+	// Sorted yields entries ordered by path. The whole scan is buffered
+	// before the first entry is yielded.
+	Sorted bool
+}
+
+// ScanError describes a path that could not be scanned in tolerant mode.
+type ScanError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the underlying error message.
+func (e ScanError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e ScanError) Unwrap() error { return e.Err }
+
+// PartialError is returned by Scan in tolerant mode when some paths could
+// not be scanned. The files that were scanned are returned alongside it.
+type PartialError struct {
+	Errors []ScanError
+}
+
+// Error summarises the number of failed paths.
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("scanner: %d path(s) could not be scanned", len(e.Errors))
 }
 
 // Scanner walks a directory and collects file metadata according to the
@@ -39,86 +121,448 @@ func New(opts Options) *Scanner {
 	return &Scanner{opts: opts}
 }
 
-// Scan walks source and returns metadata for every file that matches the
-// scanner's options. Directories themselves are never included in the results.
-func (s *Scanner) Scan(source string) ([]FileInfo, error) {
-	info, err := os.Stat(source)
+// osReadDir lists a directory. Benchmarks replace it to simulate the
+// latency of network file systems.
+var osReadDir = os.ReadDir
+
+// errStop is returned internally once the consumer stops iterating.
+var errStop = errors.New("scanner: iteration stopped")
+
+// walk holds the state of a single scan.
+type walk struct {
+	ctx      context.Context
+	source   string
+	tolerant bool
+	skipDirs map[string]bool
+	opaque   ignore.Stack
+	// visited records the resolved path of every directory entered while
+	// following symlinks, so link cycles are walked only once. mu guards it
+	// during a parallel walk.
+	mu      sync.Mutex
+	visited map[string]bool
+	yield   func(FileInfo, error) bool
+}
+
+// dirTask is a directory waiting to be read, whose children are at depth.
+type dirTask struct {
+	dir   string
+	depth int
+	stack ignore.Stack
+}
+
+// emit hands fi to the consumer.
+func (w *walk) emit(fi FileInfo) error {
+	if !w.yield(fi, nil) {
+		return errStop
+	}
+	return nil
+}
+
+// enter records the resolved form of dir as visited and reports whether it
+// had not been entered before. It always succeeds when links are not followed.
+func (w *walk) enter(dir string) (bool, error) {
+	if w.visited == nil {
+		return true, nil
+	}
+	real, err := filepath.EvalSymlinks(dir)
 	if err != nil {
-		return nil, fmt.Errorf("scanner: stat source %q: %w", source, err)
+		return false, fmt.Errorf("scanner: resolve %q: %w", dir, err)
 	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("scanner: source %q is not a directory", source)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.visited[real] {
+		return false, nil
 	}
+	w.visited[real] = true
+	return true, nil
+}
 
+// fail reports err against path as a ScanError and returns nil in tolerant
+// mode so the walk continues; otherwise it returns err unchanged.
+func (w *walk) fail(path string, err error) error {
+	if !w.tolerant {
+		return err
+	}
+	if !w.yield(FileInfo{}, ScanError{Path: path, Err: err}) {
+		return errStop
+	}
+	return nil
+}
+
+// Scan walks source and returns metadata for every file that matches the
+// scanner's options. Directories are only included when they match an
+// Opaque pattern, in which case their contents are not scanned. If ctx is
+// cancelled the walk stops and ctx's error is returned.
+func (s *Scanner) Scan(ctx context.Context, source string) ([]FileInfo, error) {
 	var files []FileInfo
+	var errs []ScanError
 
-	if s.opts.Recursive {
-		err = filepath.WalkDir(source, func(path string, d fs.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return fmt.Errorf("scanner: walk %q: %w", path, walkErr)
+	for fi, err := range s.Files(ctx, source) {
+		if err != nil {
+			var se ScanError
+			if errors.As(err, &se) {
+				errs = append(errs, se)
+				continue
 			}
+			return nil, err
+		}
+		files = append(files, fi)
+	}
 
-			name := d.Name()
+	if len(errs) > 0 {
+		return files, &PartialError{Errors: errs}
+	}
+	return files, nil
+}
 
-			// Skip hidden entries unless configured otherwise.
-			if !s.opts.IncludeHidden && strings.HasPrefix(name, ".") {
-				if d.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
+// Files returns an iterator over the files Scan would return, yielding each
+// one as soon as it is found. Only one directory listing per level is held
+// in memory, and the walk advances only when the consumer asks for the next
+// file. In tolerant mode per-path failures are yielded as ScanError values
+// and iteration continues; any other error ends the iteration. Cancelling
+// ctx ends the iteration with ctx's error.
+func (s *Scanner) Files(ctx context.Context, source string) iter.Seq2[FileInfo, error] {
+	if s.opts.Sorted {
+		return sortByPath(s.files(ctx, source))
+	}
+	return s.files(ctx, source)
+}
 
-			if d.IsDir() {
-				return nil
-			}
+// files is Files without sorting.
+func (s *Scanner) files(ctx context.Context, source string) iter.Seq2[FileInfo, error] {
+	return func(yield func(FileInfo, error) bool) {
+		info, err := os.Stat(source)
+		if err != nil {
+			yield(FileInfo{}, fmt.Errorf("scanner: stat source %q: %w", source, err))
+			return
+		}
+		if !info.IsDir() {
+			yield(FileInfo{}, fmt.Errorf("scanner: source %q is not a directory", source))
+			return
+		}
 
-			fi, infoErr := d.Info()
-			if infoErr != nil {
-				return fmt.Errorf("scanner: file info %q: %w", path, infoErr)
-			}
+		rootIgnores, err := s.rootIgnores(source)
+		if err != nil {
+			yield(FileInfo{}, err)
+			return
+		}
 
-			files = append(files, FileInfo{
-				Path:      path,
-				Name:      name,
-				Extension: strings.ToLower(filepath.Ext(name)),
-				Size:      fi.Size(),
-				ModTime:   fi.ModTime(),
-			})
-			return nil
-		})
+		opaque, err := ignore.NewList(source, s.opts.Opaque)
 		if err != nil {
+			yield(FileInfo{}, fmt.Errorf("scanner: opaque: %w", err))
+			return
+		}
+
+		w := &walk{
+			ctx:      ctx,
+			source:   source,
+			tolerant: s.opts.Tolerant,
+			skipDirs: s.skipDirs(source),
+			opaque:   ignore.Stack{opaque},
+			yield:    yield,
+		}
+		if s.opts.Symlinks == internal.SymlinkFollow {
+			w.visited = make(map[string]bool)
+			if real, err := filepath.EvalSymlinks(source); err == nil {
+				w.visited[real] = true
+			}
+		}
+
+		root := dirTask{dir: source, depth: 1, stack: rootIgnores}
+		if s.opts.Workers > 1 && s.opts.Recursive {
+			err = s.walkParallel(w, root)
+		} else {
+			err = s.walkSequential(w, root)
+		}
+		if err != nil && !errors.Is(err, errStop) {
+			yield(FileInfo{}, err)
+		}
+	}
+}
+
+// walkSequential reads t and then each of its subdirectories in turn.
+func (s *Scanner) walkSequential(w *walk, t dirTask) error {
+	subdirs, err := s.readDir(w, t)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subdirs {
+		if err := s.walkSequential(w, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir emits the entries of t.dir and returns the subdirectories to walk
+// next. Subdirectories are only returned when the scan is recursive.
+func (s *Scanner) readDir(w *walk, t dirTask) ([]dirTask, error) {
+	dir, depth, stack := t.dir, t.depth, t.stack
+	if err := w.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := osReadDir(dir)
+	if err != nil {
+		err = fmt.Errorf("scanner: read dir %q: %w", dir, err)
+		if dir == w.source {
 			return nil, err
 		}
-	} else {
-		entries, readErr := os.ReadDir(source)
-		if readErr != nil {
-			return nil, fmt.Errorf("scanner: read dir %q: %w", source, readErr)
+		return nil, w.fail(dir, err)
+	}
+
+	var subdirs []dirTask
+
+	for _, entry := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		c := candidate{
+			name:  name,
+			path:  path,
+			depth: depth,
+			link:  entry.Type()&fs.ModeSymlink != 0,
+			isDir: entry.IsDir(),
+			typ:   typeOf(entry.Type()),
 		}
 
-		for _, entry := range entries {
-			if entry.IsDir() {
+		var link FileInfo
+		if c.link {
+			if o := s.decide(c, stack, w.opaque, w.skipDirs); o == skipHidden || o == skipLink {
 				continue
 			}
+			resolved, linkErr := resolveLink(path, entry)
+			if linkErr != nil {
+				if err := w.fail(path, linkErr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			link, c.isDir = s.applyLinkPolicy(resolved)
+			link.Depth = depth
+			c.typ = link.Type
+		}
 
-			name := entry.Name()
-			if !s.opts.IncludeHidden && strings.HasPrefix(name, ".") {
+		switch s.decide(c, stack, w.opaque, w.skipDirs) {
+		case emitEntry:
+			// Links that are not followed are reported as a single entry.
+			fi := link
+			if !c.link {
+				info, err := entry.Info()
+				if err != nil {
+					if err := w.fail(path, fmt.Errorf("scanner: file info %q: %w", path, err)); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				fi = newFileInfo(path, info, depth)
+			}
+			if err := w.emit(fi); err != nil {
+				return nil, err
+			}
+
+		case emitWhole:
+			info, err := os.Stat(path)
+			if err != nil {
+				if err := w.fail(path, fmt.Errorf("scanner: file info %q: %w", path, err)); err != nil {
+					return nil, err
+				}
 				continue
 			}
+			if err := w.emit(newFileInfo(path, info, depth)); err != nil {
+				return nil, err
+			}
 
-			fi, infoErr := entry.Info()
-			if infoErr != nil {
-				return nil, fmt.Errorf("scanner: file info %q: %w", name, infoErr)
+		case descend:
+			first, enterErr := w.enter(path)
+			if enterErr != nil {
+				if err := w.fail(path, enterErr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if !first {
+				continue
 			}
 
-			files = append(files, FileInfo{
-				Path:      filepath.Join(source, name),
-				Name:      name,
-				Extension: strings.ToLower(filepath.Ext(name)),
-				Size:      fi.Size(),
-				ModTime:   fi.ModTime(),
-			})
+			list, loadErr := ignore.Load(path, internal.IgnoreFile)
+			if loadErr != nil {
+				if err := w.fail(path, fmt.Errorf("scanner: %w", loadErr)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			subdirs = append(subdirs, dirTask{dir: path, depth: depth + 1, stack: stack.Push(list)})
 		}
 	}
 
-	return files, nil
+	return subdirs, nil
+}
+
+// resolveLink describes the symbolic link at path. Size and ModTime come
+// from the target when it exists and from the link itself otherwise.
+func resolveLink(path string, entry fs.DirEntry) (FileInfo, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("scanner: read link %q: %w", path, err)
+	}
+
+	fi, err := os.Stat(path)
+	broken := false
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return FileInfo{}, fmt.Errorf("scanner: stat link target %q: %w", path, err)
+		}
+		broken = true
+		if fi, err = entry.Info(); err != nil {
+			return FileInfo{}, fmt.Errorf("scanner: file info %q: %w", path, err)
+		}
+	}
+
+	info := newFileInfo(path, fi, 0)
+	info.Name = entry.Name()
+	info.Extension = strings.ToLower(filepath.Ext(info.Name))
+	info.IsSymlink = true
+	info.LinkTarget = target
+	info.Broken = broken
+	return info, nil
+}
+
+// newFileInfo builds a FileInfo for the entry at path.
+func newFileInfo(path string, fi fs.FileInfo, depth int) FileInfo {
+	name := fi.Name()
+	return FileInfo{
+		Path:      path,
+		Name:      name,
+		Extension: strings.ToLower(filepath.Ext(name)),
+		Size:      fi.Size(),
+		ModTime:   fi.ModTime(),
+		Depth:     depth,
+		IsDir:     fi.IsDir(),
+		Type:      typeOf(fi.Mode()),
+		Inode:     inodeOf(fi),
+	}
+}
+
+// typeOf classifies a file mode as one of the internal.FileType* names.
+func typeOf(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return internal.FileTypeRegular
+	case mode.IsDir():
+		return internal.FileTypeDir
+	case mode&fs.ModeSymlink != 0:
+		return internal.FileTypeSymlink
+	case mode&fs.ModeNamedPipe != 0:
+		return internal.FileTypeFIFO
+	case mode&fs.ModeSocket != 0:
+		return internal.FileTypeSocket
+	case mode&fs.ModeCharDevice != 0:
+		return internal.FileTypeCharDevice
+	case mode&fs.ModeDevice != 0:
+		return internal.FileTypeDevice
+	default:
+		return internal.FileTypeIrregular
+	}
+}
+
+// typeAllowed reports whether entries of type t should be collected.
+func (s *Scanner) typeAllowed(t string) bool {
+	if !internal.SpecialFileType(t) {
+		return true
+	}
+	for _, want := range s.opts.Types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// depthAllowed reports whether an entry at depth satisfies MinDepth and MaxDepth.
+func (s *Scanner) depthAllowed(depth int) bool {
+	if depth < s.opts.MinDepth {
+		return false
+	}
+	return s.opts.MaxDepth == 0 || depth <= s.opts.MaxDepth
+}
+
+// rootIgnores builds the ignore stack for the source directory from the
+// configured exclude patterns followed by the source's own .forgignore.
+func (s *Scanner) rootIgnores(source string) (ignore.Stack, error) {
+	excludes, err := ignore.NewList(source, s.opts.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("scanner: exclude: %w", err)
+	}
+
+	list, err := ignore.Load(source, internal.IgnoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("scanner: %w", err)
+	}
+
+	return ignore.Stack{excludes}.Push(list), nil
+}
+
+// skipDirs maps each configured SkipDirs entry under source to the path form
+// the walk will report for it, so pruning is a simple lookup.
+func (s *Scanner) skipDirs(source string) map[string]bool {
+	skip := make(map[string]bool, len(s.opts.SkipDirs))
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return skip
+	}
+
+	for _, dir := range s.opts.SkipDirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absSource, absDir)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		skip[filepath.Join(source, rel)] = true
+	}
+	return skip
+}
+
+// sortByPath buffers seq and yields its entries ordered by path. ScanError
+// values are ordered by the path they describe; an error that ends seq is
+// yielded after the entries that preceded it.
+func sortByPath(seq iter.Seq2[FileInfo, error]) iter.Seq2[FileInfo, error] {
+	type entry struct {
+		path string
+		fi   FileInfo
+		err  error
+	}
+
+	return func(yield func(FileInfo, error) bool) {
+		var entries []entry
+		var fatal error
+		for fi, err := range seq {
+			if err != nil {
+				var se ScanError
+				if !errors.As(err, &se) {
+					fatal = err
+					break
+				}
+				entries = append(entries, entry{path: se.Path, err: err})
+				continue
+			}
+			entries = append(entries, entry{path: fi.Path, fi: fi})
+		}
+
+		slices.SortStableFunc(entries, func(a, b entry) int { return strings.Compare(a.path, b.path) })
+		for _, e := range entries {
+			if !yield(e.fi, e.err) {
+				return
+			}
+		}
+		if fatal != nil {
+			yield(FileInfo{}, fatal)
+		}
+	}
 }
@@ -2,10 +2,16 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 // createFile is a test helper that writes content to the given path,
@@ -29,7 +35,7 @@ func TestScan_BasicFiles(t *testing.T) {
 	createFile(t, filepath.Join(dir, "data.csv"), "a,b,c")
 
 	s := New(Options{})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -76,7 +82,7 @@ func TestScan_SkipsHiddenFiles(t *testing.T) {
 	createFile(t, filepath.Join(dir, ".hidden"), "h")
 
 	s := New(Options{IncludeHidden: false})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -96,7 +102,7 @@ func TestScan_IncludesHiddenFiles(t *testing.T) {
 	createFile(t, filepath.Join(dir, ".hidden"), "h")
 
 	s := New(Options{IncludeHidden: true})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -123,7 +129,7 @@ func TestScan_NonRecursive(t *testing.T) {
 	createFile(t, filepath.Join(dir, "sub", "nested.txt"), "nested")
 
 	s := New(Options{Recursive: false})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -143,7 +149,7 @@ func TestScan_Recursive(t *testing.T) {
 	createFile(t, filepath.Join(dir, "sub", "nested.txt"), "nested")
 
 	s := New(Options{Recursive: true})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -167,7 +173,7 @@ func TestScan_EmptyDirectory(t *testing.T) {
 	dir := t.TempDir()
 
 	s := New(Options{})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -190,7 +196,7 @@ func TestScan_SkipsDirectories(t *testing.T) {
 	}
 
 	s := New(Options{})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -205,7 +211,7 @@ func TestScan_SkipsDirectories(t *testing.T) {
 
 func TestScan_SourceNotExists(t *testing.T) {
 	s := New(Options{})
-	_, err := s.Scan("/nonexistent/path")
+	_, err := s.Scan(t.Context(), "/nonexistent/path")
 	if err == nil {
 		t.Fatal("expected error for non-existent source, got nil")
 	}
@@ -217,7 +223,7 @@ func TestScan_SourceIsFile(t *testing.T) {
 	createFile(t, fp, "data")
 
 	s := New(Options{})
-	_, err := s.Scan(fp)
+	_, err := s.Scan(t.Context(), fp)
 	if err == nil {
 		t.Fatal("expected error when source is a file, got nil")
 	}
@@ -230,7 +236,7 @@ func TestScan_FileMetadata(t *testing.T) {
 	createFile(t, filepath.Join(dir, "meta.txt"), content)
 
 	s := New(Options{})
-	files, err := s.Scan(dir)
+	files, err := s.Scan(t.Context(), dir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -254,3 +260,780 @@ func TestScan_FileMetadata(t *testing.T) {
 		t.Errorf("Extension = %q, want %q", f.Extension, ".txt")
 	}
 }
+
+func TestScan_ExcludePatterns(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "keep.txt"), "k")
+	createFile(t, filepath.Join(dir, "debug.log"), "l")
+	createFile(t, filepath.Join(dir, "node_modules", "pkg", "index.js"), "js")
+	createFile(t, filepath.Join(dir, "keep", "photo.jpg"), "img")
+	createFile(t, filepath.Join(dir, "sub", "nested.log"), "l")
+
+	s := New(Options{
+		Recursive: true,
+		Exclude:   []string{"*.log", "node_modules/", "/keep/"},
+	})
+	files, err := s.Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(files); got != 1 {
+		t.Fatalf("expected 1 file, got %d: %v", got, files)
+	}
+	if files[0].Name != "keep.txt" {
+		t.Errorf("Name = %q, want %q", files[0].Name, "keep.txt")
+	}
+}
+
+func TestScan_ForgIgnore(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, ".forgignore"), "*.tmp\nvendor/\n")
+	createFile(t, filepath.Join(dir, "a.tmp"), "t")
+	createFile(t, filepath.Join(dir, "a.txt"), "t")
+	createFile(t, filepath.Join(dir, "vendor", "lib.go"), "go")
+	createFile(t, filepath.Join(dir, "sub", ".forgignore"), "!wanted.tmp\nlocal.txt\n")
+	createFile(t, filepath.Join(dir, "sub", "wanted.tmp"), "t")
+	createFile(t, filepath.Join(dir, "sub", "other.tmp"), "t")
+	createFile(t, filepath.Join(dir, "sub", "local.txt"), "t")
+	createFile(t, filepath.Join(dir, "local.txt"), "t")
+
+	s := New(Options{Recursive: true, IncludeHidden: true})
+	files, err := s.Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+
+	want := []string{"a.txt", "local.txt", "sub/wanted.tmp"}
+	if len(got) != len(want) {
+		t.Fatalf("got files %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("files[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScan_ForgIgnoreNonRecursive(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, ".forgignore"), "*.part\n")
+	createFile(t, filepath.Join(dir, "movie.part"), "p")
+	createFile(t, filepath.Join(dir, "movie.mp4"), "m")
+
+	s := New(Options{})
+	files, err := s.Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(files); got != 1 {
+		t.Fatalf("expected 1 file, got %d", got)
+	}
+	if files[0].Name != "movie.mp4" {
+		t.Errorf("Name = %q, want %q", files[0].Name, "movie.mp4")
+	}
+}
+
+func TestScan_InvalidExclude(t *testing.T) {
+	s := New(Options{Exclude: []string{"[oops"}})
+	if _, err := s.Scan(t.Context(), t.TempDir()); err == nil {
+		t.Fatal("expected error for invalid exclude pattern, got nil")
+	}
+}
+
+func TestScan_SkipDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "new.jpg"), "img")
+	createFile(t, filepath.Join(dir, "Images", "sorted.jpg"), "img")
+	createFile(t, filepath.Join(dir, "other", "nested.jpg"), "img")
+
+	s := New(Options{
+		Recursive: true,
+		SkipDirs:  []string{filepath.Join(dir, "Images"), "/somewhere/else"},
+	})
+	files, err := s.Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, f := range files {
+		names[f.Name] = true
+	}
+	if names["sorted.jpg"] {
+		t.Error("files under a skipped directory should not be scanned")
+	}
+	for _, want := range []string{"new.jpg", "nested.jpg"} {
+		if !names[want] {
+			t.Errorf("expected file %q in results", want)
+		}
+	}
+}
+
+func TestScan_DepthLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "d1.txt"), "1")
+	createFile(t, filepath.Join(dir, "a", "d2.txt"), "2")
+	createFile(t, filepath.Join(dir, "a", "b", "d3.txt"), "3")
+	createFile(t, filepath.Join(dir, "a", "b", "c", "d4.txt"), "4")
+
+	tests := []struct {
+		name     string
+		minDepth int
+		maxDepth int
+		want     []string
+	}{
+		{"unlimited", 0, 0, []string{"d1.txt", "d2.txt", "d3.txt", "d4.txt"}},
+		{"max depth 2", 0, 2, []string{"d1.txt", "d2.txt"}},
+		{"min depth 3", 3, 0, []string{"d3.txt", "d4.txt"}},
+		{"exactly depth 3", 3, 3, []string{"d3.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Options{Recursive: true, MinDepth: tt.minDepth, MaxDepth: tt.maxDepth})
+			files, err := s.Scan(t.Context(), dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, f := range files {
+				got = append(got, f.Name)
+				if want := int(f.Name[1] - '0'); f.Depth != want {
+					t.Errorf("%s: Depth = %d, want %d", f.Name, f.Depth, want)
+				}
+			}
+			sort.Strings(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("files[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScan_OpaqueDirectories(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "Tool.app", "Contents", "Info.plist"), "plist")
+	createFile(t, filepath.Join(dir, "proj", "exports", "deep", "out.csv"), "csv")
+	createFile(t, filepath.Join(dir, "proj", "notes.txt"), "txt")
+
+	t.Run("recursive", func(t *testing.T) {
+		s := New(Options{Recursive: true, Opaque: []string{"*.app", "exports/"}})
+		files, err := s.Scan(t.Context(), dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		byName := make(map[string]FileInfo)
+		for _, f := range files {
+			byName[f.Name] = f
+		}
+		if len(byName) != 3 {
+			t.Fatalf("expected 3 entries, got %v", files)
+		}
+		app, ok := byName["Tool.app"]
+		if !ok || !app.IsDir || app.Extension != ".app" {
+			t.Errorf("expected Tool.app as an opaque directory entry, got %+v", app)
+		}
+		if exports, ok := byName["exports"]; !ok || !exports.IsDir || exports.Depth != 2 {
+			t.Errorf("expected exports at depth 2 as an opaque directory, got %+v", exports)
+		}
+		if _, ok := byName["Info.plist"]; ok {
+			t.Error("contents of an opaque directory should not be scanned")
+		}
+	})
+
+	t.Run("non-recursive", func(t *testing.T) {
+		s := New(Options{Opaque: []string{"*.app"}})
+		files, err := s.Scan(t.Context(), dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0].Name != "Tool.app" {
+			t.Errorf("expected only Tool.app, got %v", files)
+		}
+	})
+}
+
+// createSymlink is a test helper that creates a symbolic link at path
+// pointing to target.
+func createSymlink(t *testing.T, target, path string) {
+	t.Helper()
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatalf("creating symlink %q: %v", path, err)
+	}
+}
+
+func TestScan_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "real.txt"), "real")
+	createFile(t, filepath.Join(outside, "target.pdf"), "pdf content")
+	createFile(t, filepath.Join(outside, "linked", "inner.txt"), "inner")
+	createSymlink(t, filepath.Join(outside, "target.pdf"), filepath.Join(dir, "doc.pdf"))
+	createSymlink(t, "missing.txt", filepath.Join(dir, "dangling.txt"))
+	createSymlink(t, filepath.Join(outside, "linked"), filepath.Join(dir, "linkdir"))
+
+	scan := func(t *testing.T, opts Options) map[string]FileInfo {
+		t.Helper()
+		files, err := New(opts).Scan(t.Context(), dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		byName := make(map[string]FileInfo)
+		for _, f := range files {
+			byName[f.Name] = f
+		}
+		return byName
+	}
+
+	t.Run("skip by default", func(t *testing.T) {
+		got := scan(t, Options{Recursive: true})
+		if len(got) != 1 {
+			t.Fatalf("expected only real.txt, got %v", got)
+		}
+		if _, ok := got["real.txt"]; !ok {
+			t.Error("expected real.txt in results")
+		}
+	})
+
+	t.Run("move-link", func(t *testing.T) {
+		got := scan(t, Options{Recursive: true, Symlinks: "move-link"})
+		if len(got) != 4 {
+			t.Fatalf("expected 4 entries, got %v", got)
+		}
+
+		doc := got["doc.pdf"]
+		if !doc.IsSymlink || doc.Broken {
+			t.Errorf("doc.pdf: IsSymlink=%v Broken=%v, want true false", doc.IsSymlink, doc.Broken)
+		}
+		if doc.Size != int64(len("pdf content")) {
+			t.Errorf("doc.pdf: Size = %d, want target size %d", doc.Size, len("pdf content"))
+		}
+
+		dangling := got["dangling.txt"]
+		if !dangling.Broken || dangling.LinkTarget != "missing.txt" {
+			t.Errorf("dangling.txt: Broken=%v LinkTarget=%q", dangling.Broken, dangling.LinkTarget)
+		}
+
+		if _, ok := got["inner.txt"]; ok {
+			t.Error("move-link should not descend into linked directories")
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		got := scan(t, Options{Recursive: true, Symlinks: "follow"})
+		inner, ok := got["inner.txt"]
+		if !ok {
+			t.Fatalf("expected inner.txt from followed directory, got %v", got)
+		}
+		if want := filepath.Join(dir, "linkdir", "inner.txt"); inner.Path != want {
+			t.Errorf("inner.txt: Path = %q, want %q", inner.Path, want)
+		}
+		if _, ok := got["linkdir"]; ok {
+			t.Error("followed directory link should not be reported itself")
+		}
+		if !got["dangling.txt"].Broken {
+			t.Error("broken links should still be reported when following")
+		}
+	})
+}
+
+func TestScan_SymlinkLoop(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "sub", "file.txt"), "f")
+	createSymlink(t, dir, filepath.Join(dir, "sub", "loop"))
+	createSymlink(t, filepath.Join(dir, "sub"), filepath.Join(dir, "alias"))
+
+	s := New(Options{Recursive: true, Symlinks: "follow"})
+	files, err := s.Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(files); got != 1 {
+		t.Fatalf("expected file.txt exactly once, got %d: %v", got, files)
+	}
+}
+
+func TestScan_SpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "regular.txt"), "r")
+	sock := filepath.Join(dir, "app.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	t.Run("skipped by default", func(t *testing.T) {
+		for _, recursive := range []bool{false, true} {
+			files, err := New(Options{Recursive: recursive}).Scan(t.Context(), dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(files) != 1 || files[0].Name != "regular.txt" {
+				t.Fatalf("recursive=%v: expected only regular.txt, got %v", recursive, files)
+			}
+			if files[0].Type != "file" {
+				t.Errorf("Type = %q, want %q", files[0].Type, "file")
+			}
+		}
+	})
+
+	t.Run("included when requested", func(t *testing.T) {
+		files, err := New(Options{Types: []string{"socket"}}).Scan(t.Context(), dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 2 {
+			t.Fatalf("expected 2 entries, got %v", files)
+		}
+		for _, f := range files {
+			if f.Name == "app.sock" && f.Type != "socket" {
+				t.Errorf("app.sock: Type = %q, want %q", f.Type, "socket")
+			}
+		}
+	})
+}
+
+func TestScan_Tolerant(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "top.txt"), "top")
+	createFile(t, filepath.Join(dir, "bad", ".forgignore"), "[unterminated\n")
+	createFile(t, filepath.Join(dir, "bad", "inner.txt"), "inner")
+	createFile(t, filepath.Join(dir, "good", "nested.txt"), "nested")
+
+	t.Run("strict mode aborts", func(t *testing.T) {
+		_, err := New(Options{Recursive: true}).Scan(t.Context(), dir)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			t.Error("strict mode should not return a PartialError")
+		}
+	})
+
+	t.Run("tolerant mode continues", func(t *testing.T) {
+		files, err := New(Options{Recursive: true, Tolerant: true}).Scan(t.Context(), dir)
+
+		var partial *PartialError
+		if !errors.As(err, &partial) {
+			t.Fatalf("expected PartialError, got %v", err)
+		}
+		if len(partial.Errors) != 1 {
+			t.Fatalf("expected 1 scan error, got %v", partial.Errors)
+		}
+		if want := filepath.Join(dir, "bad"); partial.Errors[0].Path != want {
+			t.Errorf("Errors[0].Path = %q, want %q", partial.Errors[0].Path, want)
+		}
+
+		names := make(map[string]bool)
+		for _, f := range files {
+			names[f.Name] = true
+		}
+		for _, want := range []string{"top.txt", "nested.txt"} {
+			if !names[want] {
+				t.Errorf("expected %q in results despite the scan error", want)
+			}
+		}
+	})
+}
+
+func TestScan_TolerantUnreadableDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	dir := t.TempDir()
+	createFile(t, filepath.Join(dir, "top.txt"), "top")
+	locked := filepath.Join(dir, "locked")
+	createFile(t, filepath.Join(locked, "secret.txt"), "secret")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o750) })
+
+	files, err := New(Options{Recursive: true, Tolerant: true}).Scan(t.Context(), dir)
+	var partial *PartialError
+	if !errors.As(err, &partial) || len(partial.Errors) != 1 {
+		t.Fatalf("expected one scan error, got %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "top.txt" {
+		t.Errorf("expected only top.txt, got %v", files)
+	}
+}
+
+func TestScan_TolerantSourceStillFatal(t *testing.T) {
+	_, err := New(Options{Tolerant: true}).Scan(t.Context(), "/nonexistent/path")
+	if err == nil {
+		t.Fatal("expected error for missing source, got nil")
+	}
+}
+
+func TestFiles_StreamsAndStopsEarly(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		createFile(t, filepath.Join(dir, "sub", name), name)
+	}
+
+	var got []string
+	for f, err := range New(Options{Recursive: true}).Files(t.Context(), dir) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, f.Name)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	if len(got) != 2 || got[0] != "a.txt" || got[1] != "b.txt" {
+		t.Errorf("expected the first two files in lexical order, got %v", got)
+	}
+}
+
+func TestFiles_SourceError(t *testing.T) {
+	var errs int
+	for _, err := range New(Options{}).Files(t.Context(), "/nonexistent/path") {
+		if err == nil {
+			t.Fatal("expected only an error to be yielded")
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("expected 1 error, got %d", errs)
+	}
+}
+
+// createTree builds a tree of dirs directories per level, depth levels
+// deep, with files files in every directory.
+func createTree(tb testing.TB, root string, dirs, depth, files int) {
+	tb.Helper()
+	for i := range files {
+		path := filepath.Join(root, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			tb.Fatalf("writing file %q: %v", path, err)
+		}
+	}
+	if depth == 0 {
+		return
+	}
+	for i := range dirs {
+		sub := filepath.Join(root, fmt.Sprintf("dir%d", i))
+		if err := os.Mkdir(sub, 0o750); err != nil {
+			tb.Fatalf("creating directory %q: %v", sub, err)
+		}
+		createTree(tb, sub, dirs, depth-1, files)
+	}
+}
+
+func TestScan_ParallelMatchesSequential(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, 3, 3, 4)
+	createFile(t, filepath.Join(dir, "dir0", ".forgignore"), "file1.txt\n")
+	createFile(t, filepath.Join(dir, ".hidden", "secret.txt"), "s")
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "hidden", opts: Options{IncludeHidden: true}},
+		{name: "exclude", opts: Options{Exclude: []string{"dir1/"}}},
+		{name: "opaque", opts: Options{Opaque: []string{"dir2/dir2"}}},
+		{name: "depth", opts: Options{MinDepth: 2, MaxDepth: 3}},
+		{name: "skip dirs", opts: Options{SkipDirs: []string{filepath.Join(dir, "dir0", "dir1")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Recursive = true
+			opts.Sorted = true
+
+			want, err := New(opts).Scan(t.Context(), dir)
+			if err != nil {
+				t.Fatalf("sequential scan: %v", err)
+			}
+
+			opts.Workers = 8
+			got, err := New(opts).Scan(t.Context(), dir)
+			if err != nil {
+				t.Fatalf("parallel scan: %v", err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("parallel scan found %d entries, sequential found %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].Path != want[i].Path || got[i].Depth != want[i].Depth || got[i].IsDir != want[i].IsDir {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScan_SortedOrder(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, 2, 2, 2)
+
+	files, err := New(Options{Recursive: true, Workers: 4, Sorted: true}).Scan(t.Context(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 14 {
+		t.Fatalf("expected 14 files, got %d", len(files))
+	}
+	if !sort.SliceIsSorted(files, func(i, j int) bool { return files[i].Path < files[j].Path }) {
+		t.Error("expected files ordered by path")
+	}
+}
+
+func TestScan_ParallelErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "top.txt"), "top")
+	createFile(t, filepath.Join(dir, "bad", ".forgignore"), "[unterminated\n")
+	createFile(t, filepath.Join(dir, "good", "nested.txt"), "nested")
+
+	t.Run("strict mode aborts", func(t *testing.T) {
+		_, err := New(Options{Recursive: true, Workers: 4}).Scan(t.Context(), dir)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("tolerant mode continues", func(t *testing.T) {
+		files, err := New(Options{Recursive: true, Tolerant: true, Workers: 4}).Scan(t.Context(), dir)
+		var partial *PartialError
+		if !errors.As(err, &partial) || len(partial.Errors) != 1 {
+			t.Fatalf("expected one scan error, got %v", err)
+		}
+		if len(files) != 2 {
+			t.Errorf("expected top.txt and nested.txt, got %v", files)
+		}
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		_, err := New(Options{Recursive: true, Workers: 4}).Scan(t.Context(), "/nonexistent/path")
+		if err == nil {
+			t.Fatal("expected error for missing source, got nil")
+		}
+	})
+}
+
+func TestFiles_ParallelStopsEarly(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, 4, 2, 8)
+
+	var got int
+	for _, err := range New(Options{Recursive: true, Workers: 4}).Files(t.Context(), dir) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got++
+		if got == 3 {
+			break
+		}
+	}
+	if got != 3 {
+		t.Errorf("expected iteration to stop after 3 files, got %d", got)
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	dir := b.TempDir()
+	createTree(b, dir, 8, 3, 16)
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			s := New(Options{Recursive: true, Workers: workers})
+			for b.Loop() {
+				if _, err := s.Scan(b.Context(), dir); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScan_SlowFS adds a fixed delay to every directory listing, as a
+// stand-in for the round trip to an NFS or SMB server.
+func BenchmarkScan_SlowFS(b *testing.B) {
+	dir := b.TempDir()
+	createTree(b, dir, 4, 3, 8)
+
+	readDir := osReadDir
+	osReadDir = func(name string) ([]os.DirEntry, error) {
+		time.Sleep(time.Millisecond)
+		return readDir(name)
+	}
+	b.Cleanup(func() { osReadDir = readDir })
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			s := New(Options{Recursive: true, Workers: workers})
+			for b.Loop() {
+				if _, err := s.Scan(b.Context(), dir); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestFiles_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, 2, 2, 2)
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(t.Context())
+
+			var files int
+			var err error
+			for _, err = range New(Options{Recursive: true, Workers: workers}).Files(ctx, dir) {
+				if err != nil {
+					break
+				}
+				files++
+				cancel()
+			}
+			cancel()
+
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if files == 0 || files >= 14 {
+				t.Errorf("expected the walk to stop early, got %d files", files)
+			}
+		})
+	}
+}
+
+func TestDescribe_AgreesWithScan(t *testing.T) {
+	dir := t.TempDir()
+	createFile(t, filepath.Join(dir, "a.jpg"), "a")
+	createFile(t, filepath.Join(dir, ".hidden.jpg"), "h")
+	createFile(t, filepath.Join(dir, "sub", "b.jpg"), "b")
+	createFile(t, filepath.Join(dir, "sub", "c.tmp"), "c")
+	createFile(t, filepath.Join(dir, "sub", ".forgignore"), "*.tmp\n")
+	createFile(t, filepath.Join(dir, "skip", "d.jpg"), "d")
+	createFile(t, filepath.Join(dir, "dest", "e.jpg"), "e")
+	createFile(t, filepath.Join(dir, "x", "y", "z", "f.jpg"), "f")
+	createFile(t, filepath.Join(dir, ".cfg", "g.jpg"), "g")
+	createSymlink(t, "a.jpg", filepath.Join(dir, "link.jpg"))
+	createFile(t, filepath.Join(dir, "real", "h.jpg"), "h")
+	createSymlink(t, "real", filepath.Join(dir, "linkdir"))
+
+	candidates := []string{
+		"a.jpg", ".hidden.jpg", "sub/b.jpg", "sub/c.tmp", "skip/d.jpg",
+		"dest/e.jpg", "x/y/z/f.jpg", ".cfg/g.jpg", "link.jpg", "linkdir/h.jpg",
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"flat", Options{}},
+		{"recursive", Options{Recursive: true}},
+		{"hidden", Options{Recursive: true, IncludeHidden: true}},
+		{"max depth", Options{Recursive: true, MaxDepth: 2}},
+		{"min depth", Options{Recursive: true, MinDepth: 2}},
+		{"links", Options{Recursive: true, Symlinks: "move-link"}},
+		{"follow links", Options{Recursive: true, Symlinks: "follow"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Exclude = []string{"skip/"}
+			tt.opts.SkipDirs = []string{filepath.Join(dir, "dest")}
+			s := New(tt.opts)
+
+			files, err := s.Scan(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("Scan() error: %v", err)
+			}
+			scanned := make(map[string]bool)
+			for _, f := range files {
+				scanned[f.Path] = true
+			}
+
+			for _, c := range candidates {
+				path := filepath.Join(dir, filepath.FromSlash(c))
+				fi, skip, err := s.Describe(dir, path)
+				if err != nil {
+					t.Fatalf("Describe(%s) error: %v", c, err)
+				}
+				if (skip == "") != scanned[path] {
+					t.Errorf("Describe(%s) skip = %q, but scanned = %v", c, skip, scanned[path])
+				}
+				if fi.Path != path {
+					t.Errorf("Describe(%s) Path = %q", c, fi.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribe_Reasons(t *testing.T) {
+	dir := t.TempDir()
+	createFile(t, filepath.Join(dir, "sub", "b.jpg"), "b")
+	outside := filepath.Join(t.TempDir(), "o.jpg")
+	createFile(t, outside, "o")
+
+	s := New(Options{})
+	fi, skip, err := s.Describe(dir, filepath.Join(dir, "sub", "b.jpg"))
+	if err != nil {
+		t.Fatalf("Describe() error: %v", err)
+	}
+	if fi.Depth != 2 || fi.Extension != ".jpg" || fi.Size != 1 {
+		t.Errorf("unexpected FileInfo %+v", fi)
+	}
+	if !strings.Contains(skip, "not recursive") {
+		t.Errorf("skip = %q, want the scan to be non-recursive", skip)
+	}
+
+	if _, skip, _ = s.Describe(dir, outside); !strings.Contains(skip, "not inside the source") {
+		t.Errorf("skip = %q, want the file to be outside the source", skip)
+	}
+
+	createSymlink(t, "sub", filepath.Join(dir, "linkdir"))
+	s = New(Options{Recursive: true})
+	if _, skip, _ = s.Describe(dir, filepath.Join(dir, "linkdir", "b.jpg")); !strings.Contains(skip, "symbolic links are skipped") {
+		t.Errorf("skip = %q, want the linked directory to be skipped", skip)
+	}
+
+	if _, _, err := s.Describe(dir, filepath.Join(dir, "missing.jpg")); err == nil {
+		t.Error("Describe() expected error for a missing file")
+	}
+}
@@ -0,0 +1,235 @@
+// Package service generates systemd user units that run forg unattended.
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ModeTimer runs forg on a calendar schedule via a .timer unit.
+	ModeTimer = "timer"
+
+	// ModePath runs forg whenever the source directory changes via a .path unit.
+	ModePath = "path"
+
+	// DefaultName is the base name used for generated unit files.
+	DefaultName = "forg"
+
+	// DefaultSchedule is the OnCalendar expression used in timer mode.
+	DefaultSchedule = "hourly"
+)
+
+// ValidMode reports whether m is a recognised service mode.
+func ValidMode(m string) bool {
+	switch m {
+	case ModeTimer, ModePath:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidName reports whether name can be used as the base name of unit files.
+func ValidName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/ ")
+}
+
+// Options describes the units to generate.
+type Options struct {
+	// Name is the base name of the unit files (e.g. "forg" produces
+	// forg.service and forg.timer).
+	Name string
+	// Mode selects the trigger unit: ModeTimer or ModePath.
+	Mode string
+	// Binary is the absolute path to the forg executable.
+	Binary string
+	// ConfigPath is the absolute path to the configuration file.
+	ConfigPath string
+	// Sources are the directories watched in path mode.
+	Sources []string
+	// Schedule is the OnCalendar expression used in timer mode.
+	Schedule string
+	// Args holds extra arguments appended to "forg run".
+	Args []string
+}
+
+// Unit is a single generated systemd unit file.
+type Unit struct {
+	Name    string
+	Content string
+}
+
+// Generate returns the service unit followed by its trigger unit.
+func Generate(opts Options) ([]Unit, error) {
+	if opts.Name == "" {
+		opts.Name = DefaultName
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeTimer
+	}
+	if opts.Schedule == "" {
+		opts.Schedule = DefaultSchedule
+	}
+
+	if !ValidMode(opts.Mode) {
+		return nil, fmt.Errorf("invalid service mode %q: must be timer or path", opts.Mode)
+	}
+	if !ValidName(opts.Name) {
+		return nil, fmt.Errorf("invalid unit name %q", opts.Name)
+	}
+	if !filepath.IsAbs(opts.Binary) {
+		return nil, fmt.Errorf("binary path %q must be absolute", opts.Binary)
+	}
+	if !filepath.IsAbs(opts.ConfigPath) {
+		return nil, fmt.Errorf("config path %q must be absolute", opts.ConfigPath)
+	}
+	if opts.Mode == ModePath && len(opts.Sources) == 0 {
+		return nil, fmt.Errorf("path mode needs at least one source directory")
+	}
+	for _, src := range opts.Sources {
+		if opts.Mode == ModePath && !filepath.IsAbs(src) {
+			return nil, fmt.Errorf("source path %q must be absolute in path mode", src)
+		}
+	}
+
+	args := append([]string{opts.Binary, "run", "--config", opts.ConfigPath, "--quiet"}, opts.Args...)
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, quoteArg(a))
+	}
+
+	service := Unit{
+		Name: opts.Name + ".service",
+		Content: "[Unit]\n" +
+			fmt.Sprintf("Description=forg file organizer (%s)\n", escapeSpecifiers(opts.ConfigPath)) +
+			"\n" +
+			"[Service]\n" +
+			"Type=oneshot\n" +
+			fmt.Sprintf("ExecStart=%s\n", strings.Join(quoted, " ")),
+	}
+
+	var trigger Unit
+	switch opts.Mode {
+	case ModeTimer:
+		trigger = Unit{
+			Name: opts.Name + ".timer",
+			Content: "[Unit]\n" +
+				fmt.Sprintf("Description=Run %s on schedule %s\n", service.Name, opts.Schedule) +
+				"\n" +
+				"[Timer]\n" +
+				fmt.Sprintf("OnCalendar=%s\n", opts.Schedule) +
+				"Persistent=true\n" +
+				fmt.Sprintf("Unit=%s\n", service.Name) +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=timers.target\n",
+		}
+	case ModePath:
+		watched := make([]string, 0, len(opts.Sources))
+		paths := ""
+		for _, src := range opts.Sources {
+			watched = append(watched, escapeSpecifiers(src))
+			paths += fmt.Sprintf("PathChanged=%s\n", escapeSpecifiers(src))
+		}
+		trigger = Unit{
+			Name: opts.Name + ".path",
+			Content: "[Unit]\n" +
+				fmt.Sprintf("Description=Run %s when %s changes\n", service.Name, strings.Join(watched, ", ")) +
+				"\n" +
+				"[Path]\n" +
+				paths +
+				fmt.Sprintf("Unit=%s\n", service.Name) +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=default.target\n",
+		}
+	}
+
+	return []Unit{service, trigger}, nil
+}
+
+// UnitNames returns every unit file name that may exist for the given base
+// name, regardless of mode.
+func UnitNames(name string) []string {
+	if name == "" {
+		name = DefaultName
+	}
+	return []string{name + ".service", name + ".timer", name + ".path"}
+}
+
+// UserUnitDir returns the systemd user unit directory, honouring
+// $XDG_CONFIG_HOME and falling back to ~/.config/systemd/user.
+func UserUnitDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// Install writes units into dir, creating it if necessary, and returns the
+// paths written. Unless force is set, nothing is written when any of the
+// units already exists, since it may have been edited by hand; the error
+// then wraps os.ErrExist.
+func Install(dir string, units []Unit, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating unit directory %s: %w", dir, err)
+	}
+
+	if !force {
+		for _, u := range units {
+			path := filepath.Join(dir, u.Name)
+			if _, err := os.Lstat(path); err == nil {
+				return nil, fmt.Errorf("unit %s: %w", path, os.ErrExist)
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(units))
+	for _, u := range units {
+		path := filepath.Join(dir, u.Name)
+		if err := os.WriteFile(path, []byte(u.Content), 0o600); err != nil {
+			return paths, fmt.Errorf("writing unit %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Uninstall removes every unit for the given base name from dir and returns
+// the paths removed. Missing units are ignored.
+func Uninstall(dir, name string) ([]string, error) {
+	var removed []string
+	for _, n := range UnitNames(name) {
+		path := filepath.Join(dir, n)
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, fmt.Errorf("removing unit %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// quoteArg quotes a command-line argument for an ExecStart= line.
+func quoteArg(s string) string {
+	s = escapeSpecifiers(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;$") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// escapeSpecifiers escapes systemd "%" specifiers in s.
+func escapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_Timer(t *testing.T) {
+	units, err := Generate(Options{
+		Binary:     "/usr/local/bin/forg",
+		ConfigPath: "/home/me/.forg.yaml",
+		Schedule:   "*:0/15",
+		Args:       []string{"--recursive"},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(units))
+	}
+	if units[0].Name != "forg.service" {
+		t.Errorf("units[0].Name = %q, want %q", units[0].Name, "forg.service")
+	}
+	if units[1].Name != "forg.timer" {
+		t.Errorf("units[1].Name = %q, want %q", units[1].Name, "forg.timer")
+	}
+
+	wantExec := "ExecStart=/usr/local/bin/forg run --config /home/me/.forg.yaml --quiet --recursive\n"
+	if !strings.Contains(units[0].Content, wantExec) {
+		t.Errorf("service unit missing %q:\n%s", wantExec, units[0].Content)
+	}
+	if !strings.Contains(units[1].Content, "OnCalendar=*:0/15\n") {
+		t.Errorf("timer unit missing schedule:\n%s", units[1].Content)
+	}
+}
+
+func TestGenerate_Path(t *testing.T) {
+	units, err := Generate(Options{
+		Name:       "downloads",
+		Mode:       ModePath,
+		Binary:     "/usr/local/bin/forg",
+		ConfigPath: "/home/me/My Configs/forg.yaml",
+		Sources:    []string{"/home/me/Downloads", "/home/me/Desktop"},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if units[1].Name != "downloads.path" {
+		t.Errorf("units[1].Name = %q, want %q", units[1].Name, "downloads.path")
+	}
+	if !strings.Contains(units[1].Content, "PathChanged=/home/me/Downloads\nPathChanged=/home/me/Desktop\n") {
+		t.Errorf("path unit missing PathChanged:\n%s", units[1].Content)
+	}
+	if !strings.Contains(units[1].Content, "Unit=downloads.service\n") {
+		t.Errorf("path unit missing Unit=:\n%s", units[1].Content)
+	}
+	if !strings.Contains(units[0].Content, `--config "/home/me/My Configs/forg.yaml"`) {
+		t.Errorf("service unit should quote config path with spaces:\n%s", units[0].Content)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"invalid mode", Options{Mode: "cron", Binary: "/bin/forg", ConfigPath: "/c.yaml"}},
+		{"relative binary", Options{Binary: "forg", ConfigPath: "/c.yaml"}},
+		{"relative config", Options{Binary: "/bin/forg", ConfigPath: "c.yaml"}},
+		{"path mode without source", Options{Mode: ModePath, Binary: "/bin/forg", ConfigPath: "/c.yaml"}},
+		{"path mode with relative source", Options{Mode: ModePath, Binary: "/bin/forg", ConfigPath: "/c.yaml", Sources: []string{"Downloads"}}},
+		{"name with slash", Options{Name: "a/b", Binary: "/bin/forg", ConfigPath: "/c.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.opts); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/usr/bin/forg", "/usr/bin/forg"},
+		{"/a b/c", `"/a b/c"`},
+		{"100%", "100%%"},
+		{"", `""`},
+		{`say "hi"`, `"say \"hi\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := quoteArg(tt.input); got != tt.want {
+				t.Errorf("quoteArg(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallUninstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "systemd", "user")
+
+	units, err := Generate(Options{Binary: "/bin/forg", ConfigPath: "/c.yaml"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	paths, err := Install(dir, units, false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(paths))
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s to exist: %v", p, err)
+		}
+	}
+
+	removed, err := Uninstall(dir, DefaultName)
+	if err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("expected 2 removed, got %d", len(removed))
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, err=%v", p, err)
+		}
+	}
+}
+
+func TestInstall_Existing(t *testing.T) {
+	dir := t.TempDir()
+
+	units, err := Generate(Options{Binary: "/bin/forg", ConfigPath: "/c.yaml"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// A hand-edited timer must survive a plain install.
+	timer := filepath.Join(dir, units[1].Name)
+	if err := os.WriteFile(timer, []byte("# edited\n"), 0o600); err != nil {
+		t.Fatalf("writing unit: %v", err)
+	}
+
+	paths, err := Install(dir, units, false)
+	if !errors.Is(err, os.ErrExist) {
+		t.Fatalf("Install() error = %v, want os.ErrExist", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("Install() wrote %v, want nothing", paths)
+	}
+	if _, err := os.Stat(filepath.Join(dir, units[0].Name)); !os.IsNotExist(err) {
+		t.Errorf("service unit was written despite the existing timer, err=%v", err)
+	}
+	if data, _ := os.ReadFile(timer); string(data) != "# edited\n" {
+		t.Errorf("existing unit was overwritten: %q", data)
+	}
+
+	if _, err := Install(dir, units, true); err != nil {
+		t.Fatalf("Install(force) error = %v", err)
+	}
+	if data, _ := os.ReadFile(timer); string(data) != units[1].Content {
+		t.Errorf("forced install left %q", data)
+	}
+}
+
+func TestUserUnitDir_XDG(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	got, err := UserUnitDir()
+	if err != nil {
+		t.Fatalf("UserUnitDir() error = %v", err)
+	}
+	want := filepath.Join(xdg, "systemd", "user")
+	if got != want {
+		t.Errorf("UserUnitDir() = %q, want %q", got, want)
+	}
+}
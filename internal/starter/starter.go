@@ -0,0 +1,183 @@
+// Package starter generates a starter configuration from the files found in
+// a directory, using the built-in category catalog.
+package starter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devaloi/forg/internal/category"
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LargeSize is the min_size of suggested rules for big files.
+	LargeSize = "100MB"
+	// StaleAge is the older_than of suggested rules for stale files.
+	StaleAge = "1y"
+)
+
+// Profile summarises the files in a directory by category.
+type Profile struct {
+	Files int
+	// Categories holds the categories with at least one file, in catalog
+	// order.
+	Categories []CategoryProfile
+	// Uncategorized counts files whose extension is in no category, most
+	// common first.
+	Uncategorized []ExtensionCount
+}
+
+// CategoryProfile counts the files found in one category.
+type CategoryProfile struct {
+	Category category.Category
+	Files    int
+	Bytes    int64
+	// Large and Stale count the files at least LargeSize in size and older
+	// than StaleAge.
+	Large int
+	Stale int
+}
+
+// ExtensionCount is the number of files sharing an extension.
+type ExtensionCount struct {
+	Extension string
+	Files     int
+}
+
+// Analyze builds a profile from files, measuring age relative to now. It
+// stops at the first error files yields.
+func Analyze(files iter.Seq2[scanner.FileInfo, error], now time.Time) (*Profile, error) {
+	largeBytes, err := config.ParseSize(LargeSize)
+	if err != nil {
+		return nil, err
+	}
+	staleSecs, err := config.ParseDuration(StaleAge)
+	if err != nil {
+		return nil, err
+	}
+	staleBefore := now.Add(-time.Duration(staleSecs) * time.Second)
+
+	p := &Profile{}
+	byName := make(map[string]*CategoryProfile)
+	other := make(map[string]int)
+
+	for f, err := range files {
+		if err != nil {
+			return nil, err
+		}
+		p.Files++
+
+		c, ok := category.ForExtension(f.Extension)
+		if !ok {
+			other[f.Extension]++
+			continue
+		}
+		cp, ok := byName[c.Name]
+		if !ok {
+			cp = &CategoryProfile{Category: c}
+			byName[c.Name] = cp
+		}
+		cp.Files++
+		cp.Bytes += f.Size
+		if f.Size >= largeBytes {
+			cp.Large++
+		}
+		if f.ModTime.Before(staleBefore) {
+			cp.Stale++
+		}
+	}
+
+	for _, c := range category.Builtin() {
+		if cp, ok := byName[c.Name]; ok {
+			p.Categories = append(p.Categories, *cp)
+		}
+	}
+	for ext, n := range other {
+		p.Uncategorized = append(p.Uncategorized, ExtensionCount{Extension: ext, Files: n})
+	}
+	slices.SortFunc(p.Uncategorized, func(a, b ExtensionCount) int {
+		return cmp.Or(cmp.Compare(b.Files, a.Files), cmp.Compare(a.Extension, b.Extension))
+	})
+
+	return p, nil
+}
+
+// Generate renders a configuration for source with one rule per category in
+// p. A category with large or stale files is preceded by rules that send
+// those to separate Large and Old folders, since the first matching rule
+// wins. It fails when no file belongs to a known category.
+func Generate(source string, p *Profile) (string, error) {
+	if len(p.Categories) == 0 {
+		return "", errors.New("no files in a known category; start from the sample config instead")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# forg configuration generated from %s\n", source)
+	fmt.Fprintf(&b, "# %d file(s) scanned", p.Files)
+	if len(p.Uncategorized) > 0 {
+		fmt.Fprintf(&b, "; not covered by any rule: %s", uncategorized(p.Uncategorized, 5))
+	}
+	b.WriteString("\n# See https://github.com/devaloi/forg for documentation\n\n")
+	src, err := yaml.Marshal(source)
+	if err != nil {
+		return "", fmt.Errorf("encoding source path: %w", err)
+	}
+	fmt.Fprintf(&b, "source: %s", src)
+	b.WriteString("conflict: rename\n\nrules:\n")
+
+	for i, cp := range p.Categories {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		c := cp.Category
+		if cp.Large > 0 {
+			fmt.Fprintf(&b, "  # %d of %d file(s) are %s or larger.\n", cp.Large, cp.Files, LargeSize)
+			writeRule(&b, "large-"+c.Name, c.Name, "min_size: "+LargeSize, c.Folder+"/Large")
+		}
+		if cp.Stale > 0 {
+			fmt.Fprintf(&b, "  # %d of %d file(s) are older than %s.\n", cp.Stale, cp.Files, StaleAge)
+			writeRule(&b, "old-"+c.Name, c.Name, "older_than: "+StaleAge, c.Folder+"/Old")
+		}
+		fmt.Fprintf(&b, "  # %s: %d file(s), %s.\n", c.Description, cp.Files, config.FormatSize(cp.Bytes))
+		writeRule(&b, c.Name, c.Name, "", c.Folder)
+	}
+
+	return b.String(), nil
+}
+
+// writeRule writes one rule matching a category, with an optional extra
+// match criterion.
+func writeRule(b *strings.Builder, name, category, extra, destination string) {
+	fmt.Fprintf(b, "  - name: %s\n", name)
+	b.WriteString("    match:\n")
+	fmt.Fprintf(b, "      category: %s\n", category)
+	if extra != "" {
+		fmt.Fprintf(b, "      %s\n", extra)
+	}
+	fmt.Fprintf(b, "    destination: %s\n", destination)
+}
+
+// uncategorized summarises the most common of counts, such as
+// ".log (12), .bak (3)", noting how many extensions were left out.
+func uncategorized(counts []ExtensionCount, limit int) string {
+	parts := make([]string, 0, min(len(counts), limit)+1)
+	for _, c := range counts[:min(len(counts), limit)] {
+		ext := c.Extension
+		if ext == "" {
+			ext = "(no extension)"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", ext, c.Files))
+	}
+	if len(counts) > limit {
+		parts = append(parts, fmt.Sprintf("%d more", len(counts)-limit))
+	}
+	return strings.Join(parts, ", ")
+}
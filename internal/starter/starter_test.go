@@ -0,0 +1,111 @@
+package starter
+
+import (
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/forg/internal/config"
+	"github.com/devaloi/forg/internal/scanner"
+)
+
+// seq yields files as a scan would.
+func seq(files ...scanner.FileInfo) iter.Seq2[scanner.FileInfo, error] {
+	return func(yield func(scanner.FileInfo, error) bool) {
+		for _, f := range files {
+			if !yield(f, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.AddDate(0, 0, -3)
+	old := now.AddDate(-2, 0, 0)
+
+	p, err := Analyze(seq(
+		scanner.FileInfo{Extension: ".jpg", Size: 10, ModTime: recent},
+		scanner.FileInfo{Extension: ".png", Size: 20, ModTime: old},
+		scanner.FileInfo{Extension: ".mkv", Size: 2 << 30, ModTime: recent},
+		scanner.FileInfo{Extension: ".log", Size: 1, ModTime: recent},
+		scanner.FileInfo{Extension: ".log", Size: 1, ModTime: recent},
+		scanner.FileInfo{Extension: "", Size: 1, ModTime: recent},
+	), now)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	if p.Files != 6 {
+		t.Errorf("Files = %d, want 6", p.Files)
+	}
+	if len(p.Categories) != 2 {
+		t.Fatalf("got %d categories, want 2: %+v", len(p.Categories), p.Categories)
+	}
+	images, videos := p.Categories[0], p.Categories[1]
+	if images.Category.Name != "images" || images.Files != 2 || images.Bytes != 30 || images.Large != 0 || images.Stale != 1 {
+		t.Errorf("images = %+v", images)
+	}
+	if videos.Category.Name != "videos" || videos.Files != 1 || videos.Large != 1 || videos.Stale != 0 {
+		t.Errorf("videos = %+v", videos)
+	}
+
+	want := []ExtensionCount{{".log", 2}, {"", 1}}
+	if len(p.Uncategorized) != len(want) || p.Uncategorized[0] != want[0] || p.Uncategorized[1] != want[1] {
+		t.Errorf("Uncategorized = %+v, want %+v", p.Uncategorized, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	now := time.Now()
+	srcDir := t.TempDir()
+
+	p, err := Analyze(seq(
+		scanner.FileInfo{Extension: ".jpg", Size: 10, ModTime: now},
+		scanner.FileInfo{Extension: ".mp4", Size: 200 << 20, ModTime: now},
+		scanner.FileInfo{Extension: ".zip", Size: 10, ModTime: now.AddDate(-3, 0, 0)},
+		scanner.FileInfo{Extension: ".log", Size: 1, ModTime: now},
+	), now)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	out, err := Generate(srcDir, p)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	cfg, err := config.Parse([]byte(out))
+	if err != nil {
+		t.Fatalf("generated config does not parse: %v\n%s", err, out)
+	}
+
+	var names []string
+	for _, r := range cfg.Rules {
+		names = append(names, r.Name)
+	}
+	if got, want := strings.Join(names, " "), "images large-videos videos old-archives archives"; got != want {
+		t.Errorf("rules = %s, want %s", got, want)
+	}
+	if cfg.Rules[1].Match.MinSize != LargeSize || cfg.Rules[3].Match.OlderThan != StaleAge {
+		t.Errorf("suggested rules = %+v, %+v", cfg.Rules[1].Match, cfg.Rules[3].Match)
+	}
+	if cfg.Rules[1].Destination != "~/Videos/Large" {
+		t.Errorf("large-videos destination = %s", cfg.Rules[1].Destination)
+	}
+	if !strings.Contains(out, "not covered by any rule: .log (1)") {
+		t.Errorf("generated config does not mention uncategorized files:\n%s", out)
+	}
+}
+
+func TestGenerate_NothingCategorized(t *testing.T) {
+	p, err := Analyze(seq(scanner.FileInfo{Extension: ".log"}), time.Now())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+	if _, err := Generate("/src", p); err == nil {
+		t.Error("Generate() expected an error when no file is categorized")
+	}
+}